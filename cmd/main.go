@@ -1,46 +1,224 @@
 package main
 
 import (
+	"context"
+	"flag"
+	"fmt"
 	"log"
+	"os"
+	"strings"
+	"time"
 
 	"notes-app/internal/delivery/http"
 	"notes-app/internal/delivery/http/middleware"
+	"notes-app/internal/domain"
 	"notes-app/internal/repository"
 	"notes-app/internal/usecase"
+	"notes-app/pkg/auth"
+	"notes-app/pkg/config"
 	"notes-app/pkg/database"
+	"notes-app/pkg/mailer"
+	"notes-app/pkg/password"
 
 	"github.com/gin-gonic/gin"
 )
 
+const usage = `Usage:
+  notes-app [serve] [--no-auto-migrate]   start the HTTP server (the default)
+  notes-app migrate up                    apply all pending migrations
+  notes-app migrate down [n]              roll back the last n migrations (default 1)
+  notes-app migrate goto <version>        migrate up or down to version
+  notes-app migrate status                list migrations and whether they are applied
+  notes-app migrate dry-run               print the SQL migrate up would run
+  notes-app migrate drift                 compare the models with the database schema
+  notes-app migrate create <name>         add empty up/down files for a new migration
+`
+
 func main() {
+	command, args := "serve", os.Args[1:]
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		command, args = args[0], args[1:]
+	}
+
+	switch command {
+	case "serve":
+		serve(args)
+	case "migrate":
+		if err := migrate(args); err != nil {
+			log.Fatal(err)
+		}
+	default:
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+}
+
+// serve runs the API. Pending migrations are applied first unless
+// --no-auto-migrate is given, for deployments that run "migrate up" as a
+// separate step; either way it refuses to start on an outdated schema.
+func serve(args []string) {
+	flags := flag.NewFlagSet("serve", flag.ExitOnError)
+	noAutoMigrate := flags.Bool("no-auto-migrate", false, "do not apply pending migrations on startup")
+	flags.Parse(args)
+
 	db := database.NewPostgresDB()
 
+	keyManager, err := auth.KeyManagerFromEnv()
+	if err != nil {
+		log.Fatal("Failed to load signing keys:", err)
+	}
+	auth.SetKeyManager(keyManager)
+
 	// Initialize services
-	migrationService, err := database.NewMigrationService(db)
+	migrationService, err := database.NewMigrationService(db, config.Duration("MIGRATION_LOCK_TIMEOUT", 5*time.Minute))
 	if err != nil {
 		log.Fatal("Failed to initialize migration service:", err)
 	}
+	if !*noAutoMigrate {
+		if err := migrationService.Up(); err != nil {
+			log.Fatal("Failed to migrate database:", err)
+		}
+	}
+	if err := migrationService.Verify(); err != nil {
+		log.Fatal("Refusing to serve: ", err, ` (run "migrate up")`)
+	}
+	drift, err := migrationService.DetectDrift()
+	if err != nil {
+		log.Fatal("Failed to check for schema drift:", err)
+	}
+	for _, d := range drift {
+		log.Printf("Warning: schema drift: %s", d)
+	}
 
 	// Repositories
 	noteRepo := repository.NewNoteRepository(db)
 	userRepo := repository.NewUserRepository(db)
+	tagRepo := repository.NewTagRepository(db)
+	notebookRepo := repository.NewNotebookRepository(db)
+	savedSearchRepo := repository.NewSavedSearchRepository(db)
+	refreshTokenRepo := repository.NewRefreshTokenRepository(db)
+	sessionRepo := repository.NewSessionRepository(db)
+	accessTokenRepo := repository.NewAccessTokenRepository(db)
+	twoFactorRepo := repository.NewTwoFactorRepository(db)
+	passwordResetRepo := repository.NewPasswordResetRepository(db)
+	emailVerificationRepo := repository.NewEmailVerificationRepository(db)
+	loginAttempts := repository.NewLoginAttemptTracker(db, domain.LoginAttemptPolicy{
+		UserThreshold:   config.Int("LOGIN_LOCKOUT_THRESHOLD", 10),
+		IPThreshold:     config.Int("LOGIN_IP_LOCKOUT_THRESHOLD", 50),
+		LockoutDuration: config.Duration("LOGIN_LOCKOUT_DURATION", 15*time.Minute),
+		BackoffBase:     config.Duration("LOGIN_BACKOFF_BASE", time.Second),
+		BackoffMax:      config.Duration("LOGIN_BACKOFF_MAX", time.Minute),
+	})
+
+	noteTransitions := domain.DefaultNoteTransitions
+	if spec := os.Getenv("NOTE_STATUS_TRANSITIONS"); spec != "" {
+		noteTransitions, err = domain.ParseNoteTransitions(spec)
+		if err != nil {
+			log.Fatal("Invalid NOTE_STATUS_TRANSITIONS:", err)
+		}
+	}
+
+	var passwordBlocklist []string
+	if path := os.Getenv("PASSWORD_BLOCKLIST_FILE"); path != "" {
+		passwordBlocklist, err = config.ListFile(path)
+		if err != nil {
+			log.Fatal("Failed to load password blocklist:", err)
+		}
+	}
+	passwordPolicy := domain.NewPasswordPolicy(config.Int("PASSWORD_MIN_LENGTH", 8), passwordBlocklist)
+	passwordHasher, err := password.NewHasher(password.Params{
+		Algorithm: config.String("PASSWORD_HASH_ALGORITHM", password.Argon2id),
+		Argon2: password.Argon2Params{
+			Memory:     uint32(config.Int("PASSWORD_ARGON2_MEMORY_KIB", int(password.DefaultArgon2Params.Memory))),
+			Time:       uint32(config.Int("PASSWORD_ARGON2_TIME", int(password.DefaultArgon2Params.Time))),
+			Threads:    uint8(config.Int("PASSWORD_ARGON2_THREADS", int(password.DefaultArgon2Params.Threads))),
+			SaltLength: password.DefaultArgon2Params.SaltLength,
+			KeyLength:  password.DefaultArgon2Params.KeyLength,
+		},
+		BcryptCost: config.Int("PASSWORD_BCRYPT_COST", password.DefaultParams.BcryptCost),
+	})
+	if err != nil {
+		log.Fatal("Invalid password hashing parameters:", err)
+	}
+	appMailer, err := mailer.New(config.String("MAILER", "log"), config.String("MAIL_DIR", "tmp/mail"), config.String("MAIL_FROM", "notes-app@localhost"))
+	if err != nil {
+		log.Fatal("Invalid MAILER:", err)
+	}
 
 	// Usecases
-	noteUsecase := usecase.NewNoteUsecase(noteRepo)
-	userUsecase := usecase.NewUserUsecase(userRepo)
+	noteUsecase := usecase.NewNoteUsecase(noteRepo, domain.RevisionRetention{
+		KeepLast: config.Int("REVISION_KEEP_LAST", 0),
+		KeepFor:  config.Duration("REVISION_KEEP_FOR", 0),
+	}, noteTransitions)
+	twoFactorUsecase := usecase.NewTwoFactorUsecase(twoFactorRepo, config.String("TOTP_ISSUER", "Notes App"), time.Now)
+	emailUsecase := usecase.NewEmailUsecase(userRepo, emailVerificationRepo, loginAttempts, passwordHasher,
+		appMailer, domain.EmailVerification{
+			TTL: config.Duration("EMAIL_VERIFICATION_TTL", 24*time.Hour),
+			URL: os.Getenv("EMAIL_VERIFICATION_URL"),
+		})
+	userUsecase := usecase.NewUserUsecase(userRepo, refreshTokenRepo, sessionRepo, twoFactorUsecase, emailUsecase, loginAttempts, passwordPolicy, passwordHasher, config.List("ADMIN_USERNAMES"))
+	tagUsecase := usecase.NewTagUsecase(tagRepo)
+	notebookUsecase := usecase.NewNotebookUsecase(notebookRepo)
+	savedSearchUsecase := usecase.NewSavedSearchUsecase(savedSearchRepo, noteUsecase)
+	sessionUsecase := usecase.NewSessionUsecase(sessionRepo, refreshTokenRepo)
+	accessTokenUsecase := usecase.NewAccessTokenUsecase(accessTokenRepo, userRepo)
+	passwordUsecase := usecase.NewPasswordUsecase(userRepo, passwordResetRepo, sessionRepo, refreshTokenRepo, loginAttempts, passwordPolicy, passwordHasher,
+		appMailer, domain.PasswordReset{
+			TTL: config.Duration("PASSWORD_RESET_TTL", time.Hour),
+			URL: os.Getenv("PASSWORD_RESET_URL"),
+		})
+	adminUsecase := usecase.NewAdminUsecase(userRepo, sessionRepo, refreshTokenRepo, loginAttempts, passwordHasher)
+
+	if err := userUsecase.BootstrapAdmins(); err != nil {
+		log.Fatal("Failed to promote configured admins:", err)
+	}
+
+	// Background jobs
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	trashPurger, err := usecase.NewTrashPurger(noteRepo,
+		config.Duration("TRASH_RETENTION", 30*24*time.Hour),
+		config.Duration("TRASH_PURGE_INTERVAL", time.Hour),
+	)
+	if err != nil {
+		log.Fatal("Invalid TRASH_RETENTION/TRASH_PURGE_INTERVAL:", err)
+	}
+	go trashPurger.Run(ctx)
 
 	r := gin.Default()
+	// Client IPs key the login lockout, so X-Forwarded-For is only believed
+	// from the proxies listed here; by default the peer address is used.
+	if err := r.SetTrustedProxies(config.List("TRUSTED_PROXIES")); err != nil {
+		log.Fatal("Invalid TRUSTED_PROXIES:", err)
+	}
 
 	// Public routes group
 	public := r.Group("")
 	http.NewAuthHandler(public, userUsecase)
+	http.NewPasswordResetHandler(public, passwordUsecase)
+	http.NewEmailVerificationHandler(public, emailUsecase)
+	http.NewJWKSHandler(public, keyManager)
 
 	// Protected routes
 	protected := r.Group("")
-	protected.Use(middleware.AuthMiddleware(userUsecase))
+	protected.Use(middleware.AuthMiddleware(userUsecase, accessTokenUsecase))
 	{
 		http.NewNoteHandler(protected, noteUsecase)
+		http.NewTagHandler(protected, tagUsecase)
+		http.NewNotebookHandler(protected, notebookUsecase)
+		http.NewTrashHandler(protected, noteUsecase)
+		http.NewRevisionHandler(protected, noteUsecase)
+		http.NewSavedSearchHandler(protected, savedSearchUsecase)
+		http.NewAccountHandler(protected, userUsecase, passwordUsecase, emailUsecase)
+		http.NewSessionHandler(protected, sessionUsecase)
+		http.NewAccessTokenHandler(protected, accessTokenUsecase)
+		http.NewTwoFactorHandler(protected, twoFactorUsecase)
 		http.NewMigrationHandler(protected, migrationService)
+
+		admin := protected.Group("/admin")
+		admin.Use(middleware.RequireScope(domain.ScopeAdminUsers))
+		http.NewAdminHandler(admin, adminUsecase)
 	}
 
 	if err := r.Run(":8081"); err != nil {
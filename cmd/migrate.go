@@ -0,0 +1,169 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"notes-app/pkg/config"
+	"notes-app/pkg/database"
+)
+
+// migrationsDir is where "migrate create" adds files; they are embedded into
+// the binary on the next build.
+const migrationsDir = "pkg/database/migrations"
+
+var migrationName = regexp.MustCompile(`[^a-z0-9]+`)
+
+func migrate(args []string) error {
+	if len(args) == 0 {
+		return errors.New("missing migrate command\n\n" + usage)
+	}
+	command, args := args[0], args[1:]
+
+	if command == "create" {
+		return createMigration(args)
+	}
+
+	db := database.NewPostgresDB()
+	migrationService, err := database.NewMigrationService(db, config.Duration("MIGRATION_LOCK_TIMEOUT", 5*time.Minute))
+	if err != nil {
+		return err
+	}
+
+	switch command {
+	case "up":
+		return migrationService.Up()
+	case "down":
+		n := 1
+		if len(args) > 0 {
+			if n, err = strconv.Atoi(args[0]); err != nil || n < 1 {
+				return fmt.Errorf("invalid number of migrations %q", args[0])
+			}
+		}
+		return migrationService.Down(n)
+	case "goto":
+		if len(args) != 1 {
+			return errors.New("usage: migrate goto <version>")
+		}
+		version, err := strconv.ParseInt(args[0], 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid version %q", args[0])
+		}
+		return migrationService.Goto(version)
+	case "status":
+		return printMigrationStatus(migrationService)
+	case "dry-run":
+		return printPendingMigrations(migrationService)
+	case "drift":
+		return printSchemaDrift(migrationService)
+	default:
+		return fmt.Errorf("unknown migrate command %q\n\n%s", command, usage)
+	}
+}
+
+func printMigrationStatus(migrationService *database.MigrationService) error {
+	statuses, err := migrationService.Status()
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "VERSION\tNAME\tSTATUS\tAPPLIED AT")
+	for _, status := range statuses {
+		state, appliedAt := "pending", ""
+		if status.Applied {
+			state = "applied"
+			appliedAt = status.AppliedAt.Format("2006-01-02 15:04:05")
+		}
+		if status.Modified {
+			state += " (modified)"
+		}
+		if status.Missing {
+			state += " (file missing)"
+		}
+		fmt.Fprintf(w, "%04d\t%s\t%s\t%s\n", status.Version, status.Name, state, appliedAt)
+	}
+	return w.Flush()
+}
+
+func printPendingMigrations(migrationService *database.MigrationService) error {
+	pending, err := migrationService.Pending()
+	if err != nil {
+		return err
+	}
+	if len(pending) == 0 {
+		fmt.Println("-- No pending migrations")
+		return nil
+	}
+
+	for _, m := range pending {
+		fmt.Printf("-- %s (up)\n%s\n", m, strings.TrimRight(m.Up, "\n"))
+	}
+	return nil
+}
+
+// printSchemaDrift lists the differences between the models and the database
+// and fails if there are any, so it can gate a deployment.
+func printSchemaDrift(migrationService *database.MigrationService) error {
+	drift, err := migrationService.DetectDrift()
+	if err != nil {
+		return err
+	}
+	if len(drift) == 0 {
+		fmt.Println("Schema matches the models")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "TABLE\tKIND\tNAME\tEXPECTED\tACTUAL")
+	for _, d := range drift {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", d.Table, d.Kind, d.Name, d.Expected, d.Actual)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	return fmt.Errorf("schema drift: %d differences", len(drift))
+}
+
+// createMigration adds the next numbered pair of empty up and down files.
+func createMigration(args []string) error {
+	flags := flag.NewFlagSet("migrate create", flag.ExitOnError)
+	dir := flags.String("dir", migrationsDir, "migrations directory")
+	flags.Parse(args)
+	if flags.NArg() != 1 {
+		return errors.New("usage: migrate create [--dir DIR] <name>")
+	}
+
+	name := strings.Trim(migrationName.ReplaceAllString(strings.ToLower(flags.Arg(0)), "_"), "_")
+	if name == "" {
+		return fmt.Errorf("invalid migration name %q", flags.Arg(0))
+	}
+
+	existing, err := database.LoadMigrations(os.DirFS(*dir))
+	if err != nil {
+		return err
+	}
+	version := int64(1)
+	if len(existing) > 0 {
+		version = existing[len(existing)-1].Version + 1
+	}
+
+	base := filepath.Join(*dir, fmt.Sprintf("%04d_%s", version, name))
+	for _, direction := range []string{"up", "down"} {
+		path := base + "." + direction + ".sql"
+		content := fmt.Sprintf("-- %04d_%s (%s)\n", version, name, direction)
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			return err
+		}
+		fmt.Println("Created", path)
+	}
+	return nil
+}
@@ -0,0 +1,29 @@
+// Command passwordtune benchmarks password hashing on this host and prints the
+// PASSWORD_* settings that make one hash take about -target.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"time"
+
+	"notes-app/pkg/password"
+)
+
+func main() {
+	target := flag.Duration("target", 250*time.Millisecond, "time one hash should take")
+	memory := flag.Uint("memory", uint(password.DefaultArgon2Params.Memory), "argon2id memory in KiB")
+	threads := flag.Uint("threads", uint(password.DefaultArgon2Params.Threads), "argon2id parallelism")
+	flag.Parse()
+
+	params, took := password.TuneArgon2(*target, uint32(*memory), uint8(*threads))
+	fmt.Printf("# argon2id hashes in %s\n", took.Round(time.Millisecond))
+	fmt.Printf("PASSWORD_HASH_ALGORITHM=%s\n", password.Argon2id)
+	fmt.Printf("PASSWORD_ARGON2_MEMORY_KIB=%d\n", params.Memory)
+	fmt.Printf("PASSWORD_ARGON2_TIME=%d\n", params.Time)
+	fmt.Printf("PASSWORD_ARGON2_THREADS=%d\n", params.Threads)
+
+	cost, took := password.TuneBcrypt(*target)
+	fmt.Printf("# bcrypt hashes in %s\n", took.Round(time.Millisecond))
+	fmt.Printf("PASSWORD_BCRYPT_COST=%d\n", cost)
+}
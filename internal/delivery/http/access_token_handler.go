@@ -0,0 +1,107 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"notes-app/internal/delivery/http/middleware"
+	"notes-app/internal/domain"
+	"notes-app/pkg/types"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AccessTokenHandler struct {
+	tokenUsecase domain.PersonalAccessTokenUsecase
+}
+
+func NewAccessTokenHandler(r *gin.RouterGroup, tu domain.PersonalAccessTokenUsecase) {
+	handler := &AccessTokenHandler{
+		tokenUsecase: tu,
+	}
+
+	account := middleware.RequireScope(domain.ScopeAccount)
+	r.POST("/tokens", account, handler.Create)
+	r.GET("/tokens", account, handler.GetAll)
+	r.DELETE("/tokens/:id", account, handler.Revoke)
+}
+
+// Create responds with the plain token, which is never shown again.
+func (h *AccessTokenHandler) Create(c *gin.Context) {
+	var req struct {
+		Name      string     `json:"name" binding:"required"`
+		Scopes    []string   `json:"scopes" binding:"required"`
+		ExpiresAt *time.Time `json:"expires_at"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, _ := c.Get("user")
+	userObj := user.(*domain.User)
+
+	token := domain.PersonalAccessToken{
+		Name:      req.Name,
+		Scopes:    req.Scopes,
+		ExpiresAt: req.ExpiresAt,
+	}
+	var callerScopes []string
+	if claims, ok := c.Get("claims"); ok {
+		callerScopes = claims.(*types.UserClaims).Scopes
+	}
+
+	if err := h.tokenUsecase.Create(&token, userObj, callerScopes); err != nil {
+		c.JSON(accessTokenErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, token)
+}
+
+func (h *AccessTokenHandler) GetAll(c *gin.Context) {
+	user, _ := c.Get("user")
+	userObj := user.(*domain.User)
+
+	page, err := h.tokenUsecase.GetAll(userObj, pageRequest(c))
+	if err != nil {
+		c.JSON(accessTokenErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+}
+
+func (h *AccessTokenHandler) Revoke(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return
+	}
+
+	user, _ := c.Get("user")
+	userObj := user.(*domain.User)
+
+	if err := h.tokenUsecase.Revoke(uint(id), userObj); err != nil {
+		c.JSON(accessTokenErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Access token revoked"})
+}
+
+func accessTokenErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, domain.ErrAccessTokenNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, domain.ErrScopeNotGrantable):
+		return http.StatusForbidden
+	case errors.Is(err, domain.ErrAccessTokenRequest), errors.Is(err, domain.ErrAccessTokenExpiry),
+		errors.Is(err, domain.ErrInvalidScope), isPageError(err):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
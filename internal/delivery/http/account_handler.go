@@ -0,0 +1,116 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"notes-app/internal/delivery/http/middleware"
+	"notes-app/internal/domain"
+	"notes-app/pkg/types"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AccountHandler serves the authenticated user's own account.
+type AccountHandler struct {
+	userUsecase     domain.UserUsecase
+	passwordUsecase domain.PasswordUsecase
+	emailUsecase    domain.EmailUsecase
+}
+
+func NewAccountHandler(r *gin.RouterGroup, uu domain.UserUsecase, pu domain.PasswordUsecase, eu domain.EmailUsecase) {
+	handler := &AccountHandler{
+		userUsecase:     uu,
+		passwordUsecase: pu,
+		emailUsecase:    eu,
+	}
+
+	account := middleware.RequireScope(domain.ScopeAccount)
+	r.POST("/logout-all", account, handler.LogoutAll)
+	r.POST("/me/password", account, handler.ChangePassword)
+	r.PUT("/me/email", account, handler.ChangeEmail)
+}
+
+// LogoutAll revokes every session of the user, including the calling one.
+func (h *AccountHandler) LogoutAll(c *gin.Context) {
+	user, _ := c.Get("user")
+	userObj := user.(*domain.User)
+
+	if err := h.userUsecase.LogoutAll(userObj); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Logged out of all sessions"})
+}
+
+// ChangePassword sets a new password given the current one. The calling session
+// stays logged in; all other sessions are revoked.
+func (h *AccountHandler) ChangePassword(c *gin.Context) {
+	var req struct {
+		CurrentPassword string `json:"current_password" binding:"required"`
+		NewPassword     string `json:"new_password" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, _ := c.Get("user")
+	userObj := user.(*domain.User)
+
+	var currentSessionID string
+	if claims, ok := c.Get("claims"); ok {
+		currentSessionID = claims.(*types.UserClaims).SessionID
+	}
+
+	if err := h.passwordUsecase.ChangePassword(userObj, req.CurrentPassword, req.NewPassword, currentSessionID); err != nil {
+		writeRetryAfter(c, err)
+		c.JSON(passwordErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Password changed"})
+}
+
+// ChangeEmail mails a verification link to the new address; the account keeps
+// its current address until the link is used.
+func (h *AccountHandler) ChangeEmail(c *gin.Context) {
+	var req struct {
+		Email           string `json:"email" binding:"required"`
+		CurrentPassword string `json:"current_password" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, _ := c.Get("user")
+	userObj := user.(*domain.User)
+
+	if err := h.emailUsecase.ChangeEmail(userObj, req.Email, req.CurrentPassword); err != nil {
+		writeRetryAfter(c, err)
+		c.JSON(passwordErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{"message": "A verification link was sent to the new address"})
+}
+
+func passwordErrorStatus(err error) int {
+	var throttled *domain.LoginThrottledError
+	switch {
+	case errors.As(err, &throttled) && throttled.Locked:
+		return http.StatusLocked
+	case errors.As(err, &throttled):
+		return http.StatusTooManyRequests
+	case errors.Is(err, domain.ErrWrongPassword):
+		return http.StatusForbidden
+	case errors.Is(err, domain.ErrWeakPassword), errors.Is(err, domain.ErrPasswordResetInvalid),
+		errors.Is(err, domain.ErrInvalidEmail), errors.Is(err, domain.ErrEmailVerificationInvalid):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
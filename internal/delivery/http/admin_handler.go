@@ -0,0 +1,131 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"notes-app/internal/domain"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AdminHandler serves the /admin group; the group is expected to require the
+// admin:users scope.
+type AdminHandler struct {
+	adminUsecase domain.AdminUsecase
+}
+
+func NewAdminHandler(r *gin.RouterGroup, au domain.AdminUsecase) {
+	handler := &AdminHandler{
+		adminUsecase: au,
+	}
+
+	r.GET("/users", handler.ListUsers)
+	r.GET("/users/:id", handler.GetUser)
+	r.POST("/users/:id/disable", handler.Disable)
+	r.POST("/users/:id/enable", handler.Enable)
+	r.POST("/users/:id/reset-password", handler.ResetPassword)
+	r.POST("/users/:id/unlock", handler.Unlock)
+}
+
+// ListUsers searches accounts by username with ?q=, paginated by username.
+func (h *AdminHandler) ListUsers(c *gin.Context) {
+	page, err := h.adminUsecase.ListUsers(c.Query("q"), pageRequest(c))
+	if err != nil {
+		c.JSON(adminErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+}
+
+func (h *AdminHandler) GetUser(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return
+	}
+
+	user, err := h.adminUsecase.GetUser(uint(id))
+	if err != nil {
+		c.JSON(adminErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, user)
+}
+
+func (h *AdminHandler) Disable(c *gin.Context) {
+	h.setDisabled(c, true)
+}
+
+func (h *AdminHandler) Enable(c *gin.Context) {
+	h.setDisabled(c, false)
+}
+
+func (h *AdminHandler) setDisabled(c *gin.Context, disabled bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return
+	}
+
+	admin, _ := c.Get("user")
+	adminObj := admin.(*domain.User)
+
+	user, err := h.adminUsecase.SetDisabled(uint(id), disabled, adminObj)
+	if err != nil {
+		c.JSON(adminErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, user)
+}
+
+// ResetPassword responds with the temporary password; it is not stored in
+// plain text and cannot be retrieved again.
+func (h *AdminHandler) ResetPassword(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return
+	}
+
+	password, err := h.adminUsecase.ResetPassword(uint(id))
+	if err != nil {
+		c.JSON(adminErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"temporary_password": password})
+}
+
+// Unlock lifts a lockout caused by failed logins on the user's account.
+func (h *AdminHandler) Unlock(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return
+	}
+
+	if err := h.adminUsecase.Unlock(uint(id)); err != nil {
+		c.JSON(adminErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "User unlocked"})
+}
+
+func adminErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, domain.ErrUserNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, domain.ErrCannotDisableSelf):
+		return http.StatusConflict
+	case isPageError(err):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -1,9 +1,12 @@
 package http
 
 import (
+	"errors"
 	"log"
+	"math"
 	"net/http"
 	"notes-app/internal/domain"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 )
@@ -20,7 +23,9 @@ func NewAuthHandler(r *gin.RouterGroup, uu domain.UserUsecase) {
 	// Auth routes
 	r.POST("/register", handler.Register)
 	r.POST("/login", handler.Login)
+	r.POST("/login/2fa", handler.LoginTwoFactor)
 	r.POST("/refresh", handler.RefreshToken)
+	r.POST("/logout", handler.Logout)
 }
 
 func (h *AuthHandler) Register(c *gin.Context) {
@@ -30,12 +35,12 @@ func (h *AuthHandler) Register(c *gin.Context) {
 		return
 	}
 
-	// Debug logging
-	log.Printf("Register attempt - Username: %s, Password length: %d",
-		user.Username, len(user.Password))
-
 	if err := h.userUsecase.Register(&user); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		status := http.StatusInternalServerError
+		if errors.Is(err, domain.ErrWeakPassword) || errors.Is(err, domain.ErrUsernameRequired) || errors.Is(err, domain.ErrInvalidEmail) {
+			status = http.StatusBadRequest
+		}
+		c.JSON(status, gin.H{"error": err.Error()})
 		return
 	}
 
@@ -54,19 +59,39 @@ func (h *AuthHandler) Login(c *gin.Context) {
 		return
 	}
 
-	// Debug: Print received credentials (remove in production)
-	log.Printf("Login attempt - Username: %s, Password length: %d",
-		credentials.Username, len(credentials.Password))
-
-	token, err := h.userUsecase.Login(credentials.Username, credentials.Password)
+	token, err := h.userUsecase.Login(credentials.Username, credentials.Password, clientInfo(c))
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		writeRetryAfter(c, err)
+		c.JSON(authErrorStatus(err), gin.H{"error": err.Error()})
 		return
 	}
 
 	c.JSON(http.StatusOK, token)
 }
 
+// LoginTwoFactor completes a login that answered with a challenge_token, using
+// a code from the authenticator app or a recovery code.
+func (h *AuthHandler) LoginTwoFactor(c *gin.Context) {
+	var req struct {
+		ChallengeToken string `json:"challenge_token" binding:"required"`
+		Code           string `json:"code" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	tokens, err := h.userUsecase.LoginTwoFactor(req.ChallengeToken, req.Code, clientInfo(c))
+	if err != nil {
+		writeRetryAfter(c, err)
+		c.JSON(authErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, tokens)
+}
+
 func (h *AuthHandler) RefreshToken(c *gin.Context) {
 	var req struct {
 		RefreshToken string `json:"refresh_token"`
@@ -77,11 +102,62 @@ func (h *AuthHandler) RefreshToken(c *gin.Context) {
 		return
 	}
 
-	tokens, err := h.userUsecase.RefreshToken(req.RefreshToken)
+	tokens, err := h.userUsecase.RefreshToken(req.RefreshToken, clientInfo(c))
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		c.JSON(authErrorStatus(err), gin.H{"error": err.Error()})
 		return
 	}
 
 	c.JSON(http.StatusOK, tokens)
 }
+
+func (h *AuthHandler) Logout(c *gin.Context) {
+	var req struct {
+		RefreshToken string `json:"refresh_token" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.userUsecase.Logout(req.RefreshToken); err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Logged out"})
+}
+
+func authErrorStatus(err error) int {
+	var throttled *domain.LoginThrottledError
+	switch {
+	case errors.As(err, &throttled) && throttled.Locked:
+		return http.StatusLocked
+	case errors.As(err, &throttled):
+		return http.StatusTooManyRequests
+	case errors.Is(err, domain.ErrUserDisabled):
+		return http.StatusForbidden
+	case errors.Is(err, domain.ErrTwoFactorNotSetUp):
+		return http.StatusBadRequest
+	default:
+		return http.StatusUnauthorized
+	}
+}
+
+// writeRetryAfter tells a throttled client, in whole seconds, when to retry.
+func writeRetryAfter(c *gin.Context, err error) {
+	var throttled *domain.LoginThrottledError
+	if errors.As(err, &throttled) {
+		seconds := int(math.Ceil(throttled.RetryAfter.Seconds()))
+		c.Header("Retry-After", strconv.Itoa(seconds))
+	}
+}
+
+// clientInfo describes the device making the request for session tracking.
+func clientInfo(c *gin.Context) domain.ClientInfo {
+	return domain.ClientInfo{
+		UserAgent: c.Request.UserAgent(),
+		IPAddress: c.ClientIP(),
+	}
+}
@@ -0,0 +1,31 @@
+package http
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"notes-app/internal/domain"
+)
+
+func TestAuthErrorStatusThrottled(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{"backing off", &domain.LoginThrottledError{RetryAfter: 2 * time.Second}, http.StatusTooManyRequests},
+		{"locked out", &domain.LoginThrottledError{RetryAfter: 15 * time.Minute, Locked: true}, http.StatusLocked},
+		{"wrapped lockout", fmt.Errorf("login: %w", &domain.LoginThrottledError{Locked: true}), http.StatusLocked},
+		{"wrong password", domain.ErrInvalidCredentials, http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		if got := authErrorStatus(tt.err); got != tt.want {
+			t.Errorf("%s: authErrorStatus = %d, want %d", tt.name, got, tt.want)
+		}
+		if got := passwordErrorStatus(tt.err); tt.want != http.StatusUnauthorized && got != tt.want {
+			t.Errorf("%s: passwordErrorStatus = %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,41 @@
+package http
+
+import (
+	"net/http"
+
+	"notes-app/internal/domain"
+
+	"github.com/gin-gonic/gin"
+)
+
+type EmailVerificationHandler struct {
+	emailUsecase domain.EmailUsecase
+}
+
+func NewEmailVerificationHandler(r *gin.RouterGroup, eu domain.EmailUsecase) {
+	handler := &EmailVerificationHandler{
+		emailUsecase: eu,
+	}
+
+	r.POST("/email/verify", handler.Verify)
+}
+
+// Verify is public so the link in the mail works without logging in; the
+// token alone identifies the account and the address.
+func (h *EmailVerificationHandler) Verify(c *gin.Context) {
+	var req struct {
+		Token string `json:"token" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.emailUsecase.VerifyEmail(req.Token); err != nil {
+		c.JSON(passwordErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Email address verified"})
+}
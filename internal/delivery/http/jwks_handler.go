@@ -0,0 +1,27 @@
+package http
+
+import (
+	"net/http"
+	"notes-app/pkg/auth"
+
+	"github.com/gin-gonic/gin"
+)
+
+type JWKSHandler struct {
+	keyManager *auth.KeyManager
+}
+
+func NewJWKSHandler(r *gin.RouterGroup, km *auth.KeyManager) {
+	handler := &JWKSHandler{
+		keyManager: km,
+	}
+
+	r.GET("/.well-known/jwks.json", handler.GetKeys)
+}
+
+// GetKeys publishes the public signing keys so other services can verify
+// access tokens locally, selecting the key by the token's kid header.
+func (h *JWKSHandler) GetKeys(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, h.keyManager.JWKS())
+}
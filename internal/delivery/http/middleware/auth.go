@@ -1,14 +1,18 @@
 package middleware
 
 import (
+	"errors"
 	"net/http"
 	"strings"
 
 	"github.com/gin-gonic/gin"
 	"notes-app/internal/domain"
+	"notes-app/pkg/types"
 )
 
-func AuthMiddleware(userUsecase domain.UserUsecase) gin.HandlerFunc {
+// AuthMiddleware accepts both JWT access tokens and personal access tokens,
+// which are recognised by their prefix.
+func AuthMiddleware(userUsecase domain.UserUsecase, tokenUsecase domain.PersonalAccessTokenUsecase) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
@@ -26,15 +30,28 @@ func AuthMiddleware(userUsecase domain.UserUsecase) gin.HandlerFunc {
 		}
 
 		token := splitToken[1]
-		user, err := userUsecase.ValidateAccessToken(token)
+		var user *domain.User
+		var claims *types.UserClaims
+		var err error
+		if strings.HasPrefix(token, domain.AccessTokenPrefix) {
+			user, claims, err = tokenUsecase.Authenticate(token)
+		} else {
+			user, claims, err = userUsecase.ValidateAccessToken(token)
+		}
+		if errors.Is(err, domain.ErrUserDisabled) {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			c.Abort()
+			return
+		}
 		if err != nil {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
 			c.Abort()
 			return
 		}
 
-		// Set user in context
+		// Set user and token claims in context
 		c.Set("user", user)
+		c.Set("claims", claims)
 		c.Next()
 	}
 }
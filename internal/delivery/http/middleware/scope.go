@@ -0,0 +1,40 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"notes-app/pkg/types"
+)
+
+// RequireScope rejects callers whose token lacks any of the given scopes with
+// 403, naming the first missing scope. It must run after AuthMiddleware.
+func RequireScope(scopes ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var granted []string
+		if claims, ok := c.Get("claims"); ok {
+			granted = claims.(*types.UserClaims).Scopes
+		}
+
+		for _, scope := range scopes {
+			if !HasScope(granted, scope) {
+				c.JSON(http.StatusForbidden, gin.H{
+					"error":          "insufficient scope",
+					"required_scope": scope,
+				})
+				c.Abort()
+				return
+			}
+		}
+		c.Next()
+	}
+}
+
+func HasScope(granted []string, scope string) bool {
+	for _, s := range granted {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
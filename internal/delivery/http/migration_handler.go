@@ -1,7 +1,10 @@
 package http
 
 import (
+	"errors"
 	"net/http"
+	"notes-app/internal/delivery/http/middleware"
+	"notes-app/internal/domain"
 	"notes-app/pkg/database"
 
 	"github.com/gin-gonic/gin"
@@ -17,23 +20,28 @@ func NewMigrationHandler(r *gin.RouterGroup, ms *database.MigrationService) {
 	}
 
 	// Register routes
-	r.GET("/migrations", handler.GetMigrationHistory)
-	r.GET("/migrations/latest", handler.GetLatestMigration)
+	admin := middleware.RequireScope(domain.ScopeAdminMigrations)
+	r.GET("/migrations", admin, handler.GetMigrationHistory)
+	r.GET("/migrations/latest", admin, handler.GetLatestMigration)
+	r.GET("/migrations/drift", admin, handler.GetSchemaDrift)
 }
 
+// GetMigrationHistory pages through the history, newest first:
+// ?status=FAILED&limit=20&cursor=...
 func (h *MigrationHandler) GetMigrationHistory(c *gin.Context) {
-	history, err := h.migrationService.GetMigrationHistory()
+	page, err := h.migrationService.GetMigrationHistory(c.Query("status"), pageRequest(c))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
+		status := http.StatusInternalServerError
+		if isPageError(err) || errors.Is(err, database.ErrUnknownStatus) {
+			status = http.StatusBadRequest
+		}
+		c.JSON(status, gin.H{
 			"error": "Failed to fetch migration history: " + err.Error(),
 		})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"total_migrations": len(history),
-		"migrations":       history,
-	})
+	c.JSON(http.StatusOK, page)
 }
 
 func (h *MigrationHandler) GetLatestMigration(c *gin.Context) {
@@ -47,3 +55,18 @@ func (h *MigrationHandler) GetLatestMigration(c *gin.Context) {
 
 	c.JSON(http.StatusOK, latest)
 }
+
+func (h *MigrationHandler) GetSchemaDrift(c *gin.Context) {
+	drift, err := h.migrationService.DetectDrift()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to detect schema drift: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"in_sync": len(drift) == 0,
+		"drift":   drift,
+	})
+}
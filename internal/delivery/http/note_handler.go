@@ -1,9 +1,13 @@
 package http
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
 
+	"notes-app/internal/delivery/http/middleware"
 	"notes-app/internal/domain"
 
 	"github.com/gin-gonic/gin"
@@ -18,12 +22,22 @@ func NewNoteHandler(r *gin.RouterGroup, nu domain.NoteUsecase) {
 		noteUsecase: nu,
 	}
 
-	r.POST("/notes", handler.Create)
-	r.GET("/notes", handler.GetAll)
-	r.GET("/notes/:id", handler.GetByID)
-	r.PUT("/notes/:id", handler.Update)
-	r.DELETE("/notes/:id", handler.Delete)
-	r.GET("/notes/query/:query", handler.Query)
+	read := middleware.RequireScope(domain.ScopeNotesRead)
+	write := middleware.RequireScope(domain.ScopeNotesWrite)
+	r.POST("/notes", write, handler.Create)
+	r.GET("/notes", read, handler.GetAll)
+	r.GET("/notes/:id", read, handler.GetByID)
+	r.PUT("/notes/:id", write, handler.Update)
+	r.DELETE("/notes/:id", write, handler.Delete)
+	r.GET("/notes/search", read, handler.Query)
+	r.POST("/notes/:id/tags", write, handler.AttachTags)
+	r.DELETE("/notes/:id/tags/:tag_id", write, handler.DetachTag)
+	r.POST("/notes/:id/move", write, handler.Move)
+	r.POST("/notes/:id/status", write, handler.SetStatus)
+	r.POST("/notes/:id/start", write, handler.transitionTo(domain.NoteStatusInProgress))
+	r.POST("/notes/:id/complete", write, handler.transitionTo(domain.NoteStatusDone))
+	r.POST("/notes/:id/reopen", write, handler.transitionTo(domain.NoteStatusTodo))
+	r.POST("/notes/:id/archive", write, handler.transitionTo(domain.NoteStatusArchived))
 }
 
 func (h *NoteHandler) Create(c *gin.Context) {
@@ -48,12 +62,16 @@ func (h *NoteHandler) Create(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	if !applyLegacyIsDone(c, &note) {
+		return
+	}
 
 	if err := h.noteUsecase.Create(&note, userObj); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(noteErrorStatus(err), gin.H{"error": err.Error()})
 		return
 	}
 
+	c.Header("ETag", noteETag(&note))
 	c.JSON(http.StatusCreated, note)
 }
 
@@ -61,13 +79,43 @@ func (h *NoteHandler) GetAll(c *gin.Context) {
 	user, _ := c.Get("user")
 	userObj := user.(*domain.User)
 
-	notes, err := h.noteUsecase.GetAll(userObj)
+	// ?tag=a&tag=b lists notes carrying any of the tags; add match=all to require every tag
+	filter := domain.NoteFilter{
+		Tags:         c.QueryArray("tag"),
+		MatchAllTags: c.Query("match") == "all",
+		Expression:   c.Query("filter"),
+	}
+	if raw := c.Query("notebook_id"); raw != "" {
+		notebookID, err := strconv.ParseUint(raw, 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid notebook ID"})
+			return
+		}
+		id := uint(notebookID)
+		filter.NotebookID = &id
+	}
+
+	opts, ok := listOptions(c)
+	if !ok {
+		return
+	}
+
+	page, err := h.noteUsecase.GetAll(userObj, filter, opts)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		var syntaxErr *domain.FilterSyntaxError
+		if errors.As(err, &syntaxErr) {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":    err.Error(),
+				"filter":   filter.Expression,
+				"position": syntaxErr.Position,
+			})
+			return
+		}
+		c.JSON(noteErrorStatus(err), gin.H{"error": err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusOK, notes)
+	writePage(c, page, opts.Fields)
 }
 
 func (h *NoteHandler) GetByID(c *gin.Context) {
@@ -86,6 +134,13 @@ func (h *NoteHandler) GetByID(c *gin.Context) {
 		return
 	}
 
+	etag := noteETag(note)
+	c.Header("ETag", etag)
+	if etagMatches(c.GetHeader("If-None-Match"), etag) {
+		c.Status(http.StatusNotModified)
+		return
+	}
+
 	c.JSON(http.StatusOK, note)
 }
 
@@ -101,16 +156,26 @@ func (h *NoteHandler) Update(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	if !applyLegacyIsDone(c, &note) {
+		return
+	}
+
+	version, ok := ifMatchVersion(c)
+	if !ok {
+		return
+	}
 
 	user, _ := c.Get("user")
 	userObj := user.(*domain.User)
 
 	note.ID = uint(id)
+	note.Version = version
 	if err := h.noteUsecase.Update(&note, userObj); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(noteErrorStatus(err), gin.H{"error": err.Error()})
 		return
 	}
 
+	c.Header("ETag", noteETag(&note))
 	c.JSON(http.StatusOK, note)
 }
 
@@ -121,27 +186,237 @@ func (h *NoteHandler) Delete(c *gin.Context) {
 		return
 	}
 
+	version, ok := ifMatchVersion(c)
+	if !ok {
+		return
+	}
+
 	user, _ := c.Get("user")
 	userObj := user.(*domain.User)
 
-	if err := h.noteUsecase.Delete(uint(id), userObj); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	if err := h.noteUsecase.Delete(uint(id), version, userObj); err != nil {
+		c.JSON(noteErrorStatus(err), gin.H{"error": err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Note deleted successfully"})
+	c.JSON(http.StatusOK, gin.H{"message": "Note moved to trash"})
 }
 
 func (h *NoteHandler) Query(c *gin.Context) {
-	query := c.Param("query")
+	query := c.Query("q")
+
+	opts, ok := listOptions(c)
+	if !ok {
+		return
+	}
+
+	user, _ := c.Get("user")
+	userObj := user.(*domain.User)
+
+	results, err := h.noteUsecase.Query(query, userObj, opts)
+	if err != nil {
+		c.JSON(noteErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	writePage(c, results, opts.Fields)
+}
+
+func (h *NoteHandler) AttachTags(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return
+	}
+
+	var req struct {
+		TagIDs []uint `json:"tag_ids" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, _ := c.Get("user")
+	userObj := user.(*domain.User)
+
+	note, err := h.noteUsecase.AttachTags(uint(id), req.TagIDs, userObj)
+	if err != nil {
+		c.JSON(noteErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, note)
+}
+
+func (h *NoteHandler) DetachTag(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return
+	}
+
+	tagID, err := strconv.ParseUint(c.Param("tag_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid tag ID"})
+		return
+	}
+
+	user, _ := c.Get("user")
+	userObj := user.(*domain.User)
+
+	note, err := h.noteUsecase.DetachTag(uint(id), uint(tagID), userObj)
+	if err != nil {
+		c.JSON(noteErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, note)
+}
+
+func (h *NoteHandler) Move(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return
+	}
+
+	// A null or missing notebook_id takes the note out of its notebook
+	var req struct {
+		NotebookID *uint `json:"notebook_id"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, _ := c.Get("user")
+	userObj := user.(*domain.User)
+
+	note, err := h.noteUsecase.Move(uint(id), req.NotebookID, userObj)
+	if err != nil {
+		c.JSON(noteErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, note)
+}
+
+func (h *NoteHandler) SetStatus(c *gin.Context) {
+	var req struct {
+		Status domain.NoteStatus `json:"status" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	h.transition(c, req.Status)
+}
+
+// transitionTo serves the shortcut endpoints that move a note to a fixed status.
+func (h *NoteHandler) transitionTo(status domain.NoteStatus) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		h.transition(c, status)
+	}
+}
+
+func (h *NoteHandler) transition(c *gin.Context, status domain.NoteStatus) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return
+	}
+
+	version, ok := ifMatchVersion(c)
+	if !ok {
+		return
+	}
 
 	user, _ := c.Get("user")
 	userObj := user.(*domain.User)
 
-	notes, err := h.noteUsecase.Query(query, userObj)
+	note, err := h.noteUsecase.Transition(uint(id), status, version, userObj)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(noteErrorStatus(err), gin.H{"error": err.Error()})
 		return
 	}
-	c.JSON(http.StatusOK, notes)
+
+	c.Header("ETag", noteETag(note))
+	c.JSON(http.StatusOK, note)
+}
+
+// applyLegacyIsDone translates the deprecated is_done field into a status for
+// clients that have not moved to the status field yet, flagging the response as
+// deprecated. An explicit status wins. It writes a 400 response (returning
+// false) when is_done cannot be interpreted.
+func applyLegacyIsDone(c *gin.Context, note *domain.Note) bool {
+	if note.IsDone == "" {
+		return true
+	}
+
+	status, ok := domain.StatusFromLegacyIsDone(note.IsDone)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("invalid is_done value %q, use status instead", note.IsDone)})
+		return false
+	}
+	if note.Status == "" {
+		note.Status = status
+	}
+	note.IsDone = ""
+
+	c.Header("Deprecation", "true")
+	c.Header("Warning", `299 - "is_done is deprecated and will be removed in the next version; use status"`)
+	return true
+}
+
+func noteErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, domain.ErrVersionMismatch):
+		return http.StatusPreconditionFailed
+	case errors.Is(err, domain.ErrTransitionNotAllowed):
+		return http.StatusConflict
+	case errors.Is(err, domain.ErrEmptySearchQuery), errors.Is(err, domain.ErrInvalidStatus), isPageError(err):
+		return http.StatusBadRequest
+	case errors.Is(err, domain.ErrNoteNotFound), errors.Is(err, domain.ErrTagNotFound),
+		errors.Is(err, domain.ErrNotebookNotFound), errors.Is(err, domain.ErrRevisionNotFound):
+		return http.StatusNotFound
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+func noteETag(note *domain.Note) string {
+	return fmt.Sprintf(`"%d-%d"`, note.ID, note.Version)
+}
+
+// etagMatches reports whether a comma separated If-Match / If-None-Match header
+// contains etag. Weak validators compare equal to their strong form.
+func etagMatches(header, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// ifMatchVersion extracts the expected note version from If-Match. It returns 0
+// when the header is absent or "*", and writes a 412 response (ok == false)
+// when the header is not an ETag issued by this API.
+func ifMatchVersion(c *gin.Context) (version int, ok bool) {
+	header := strings.TrimSpace(c.GetHeader("If-Match"))
+	if header == "" || header == "*" {
+		return 0, true
+	}
+
+	tag := strings.Trim(strings.TrimPrefix(header, "W/"), `"`)
+	if idx := strings.LastIndex(tag, "-"); idx >= 0 && tag[:idx] == c.Param("id") {
+		if version, err := strconv.Atoi(tag[idx+1:]); err == nil && version > 0 {
+			return version, true
+		}
+	}
+
+	c.JSON(http.StatusPreconditionFailed, gin.H{"error": domain.ErrVersionMismatch.Error()})
+	return 0, false
 }
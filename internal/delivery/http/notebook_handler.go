@@ -0,0 +1,169 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"notes-app/internal/delivery/http/middleware"
+	"notes-app/internal/domain"
+
+	"github.com/gin-gonic/gin"
+)
+
+type NotebookHandler struct {
+	notebookUsecase domain.NotebookUsecase
+}
+
+func NewNotebookHandler(r *gin.RouterGroup, nu domain.NotebookUsecase) {
+	handler := &NotebookHandler{
+		notebookUsecase: nu,
+	}
+
+	read := middleware.RequireScope(domain.ScopeNotesRead)
+	write := middleware.RequireScope(domain.ScopeNotesWrite)
+	r.POST("/notebooks", write, handler.Create)
+	r.GET("/notebooks", read, handler.GetAll)
+	r.GET("/notebooks/:id", read, handler.GetTree)
+	r.PUT("/notebooks/:id", write, handler.Rename)
+	r.POST("/notebooks/:id/move", write, handler.Move)
+	r.DELETE("/notebooks/:id", write, handler.Delete)
+}
+
+func (h *NotebookHandler) Create(c *gin.Context) {
+	var notebook domain.Notebook
+	if err := c.ShouldBindJSON(&notebook); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, _ := c.Get("user")
+	userObj := user.(*domain.User)
+
+	if err := h.notebookUsecase.Create(&notebook, userObj); err != nil {
+		c.JSON(notebookErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, notebook)
+}
+
+func (h *NotebookHandler) GetAll(c *gin.Context) {
+	user, _ := c.Get("user")
+	userObj := user.(*domain.User)
+
+	page, err := h.notebookUsecase.GetAll(userObj, pageRequest(c))
+	if err != nil {
+		c.JSON(notebookErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+}
+
+func (h *NotebookHandler) GetTree(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return
+	}
+
+	user, _ := c.Get("user")
+	userObj := user.(*domain.User)
+
+	tree, err := h.notebookUsecase.GetTree(uint(id), userObj)
+	if err != nil {
+		c.JSON(notebookErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, tree)
+}
+
+func (h *NotebookHandler) Rename(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return
+	}
+
+	var req struct {
+		Name string `json:"name"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, _ := c.Get("user")
+	userObj := user.(*domain.User)
+
+	notebook, err := h.notebookUsecase.Rename(uint(id), req.Name, userObj)
+	if err != nil {
+		c.JSON(notebookErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, notebook)
+}
+
+func (h *NotebookHandler) Move(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return
+	}
+
+	// A null or missing parent_id moves the notebook to the top level
+	var req struct {
+		ParentID *uint `json:"parent_id"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, _ := c.Get("user")
+	userObj := user.(*domain.User)
+
+	notebook, err := h.notebookUsecase.Move(uint(id), req.ParentID, userObj)
+	if err != nil {
+		c.JSON(notebookErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, notebook)
+}
+
+func (h *NotebookHandler) Delete(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return
+	}
+
+	user, _ := c.Get("user")
+	userObj := user.(*domain.User)
+
+	if err := h.notebookUsecase.Delete(uint(id), c.Query("mode"), userObj); err != nil {
+		c.JSON(notebookErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Notebook deleted successfully"})
+}
+
+func notebookErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, domain.ErrNotebookNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, domain.ErrNotebookCycle):
+		return http.StatusConflict
+	case errors.Is(err, domain.ErrNotebookNameEmpty), errors.Is(err, domain.ErrInvalidDeleteMode):
+		return http.StatusBadRequest
+	case isPageError(err):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,93 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"notes-app/internal/domain"
+
+	"github.com/gin-gonic/gin"
+)
+
+// pageRequest reads the limit and cursor query parameters shared by every list endpoint.
+func pageRequest(c *gin.Context) domain.PageRequest {
+	limit, _ := strconv.Atoi(c.Query("limit"))
+	return domain.PageRequest{
+		Limit:  limit,
+		Cursor: c.Query("cursor"),
+	}
+}
+
+// listOptions reads sort, order, fields and paging for note listings:
+// ?sort=updated_at&order=asc&fields=id,note_title&limit=20&cursor=...
+// It writes a 400 response and returns ok == false on an invalid order.
+func listOptions(c *gin.Context) (opts domain.NoteListOptions, ok bool) {
+	order := c.DefaultQuery("order", "desc")
+	if order != "asc" && order != "desc" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrInvalidSort.Error()})
+		return opts, false
+	}
+
+	opts = domain.NoteListOptions{
+		Page:       pageRequest(c),
+		SortBy:     c.Query("sort"),
+		Descending: order == "desc",
+	}
+	if fields := c.Query("fields"); fields != "" {
+		opts.Fields = strings.Split(fields, ",")
+	}
+	return opts, true
+}
+
+// writePage responds with the page, projected onto fields when any were selected.
+func writePage[T any](c *gin.Context, page domain.Page[T], fields []string) {
+	if len(fields) == 0 {
+		c.JSON(http.StatusOK, page)
+		return
+	}
+
+	projected, err := projectPage(page, fields)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, projected)
+}
+
+// isPageError reports whether err was caused by malformed paging, sorting or field selection.
+func isPageError(err error) bool {
+	return errors.Is(err, domain.ErrInvalidCursor) ||
+		errors.Is(err, domain.ErrInvalidSort) ||
+		errors.Is(err, domain.ErrUnknownField)
+}
+
+// projectPage keeps only the requested JSON fields of every item in the page.
+func projectPage[T any](page domain.Page[T], fields []string) (domain.Page[map[string]interface{}], error) {
+	projected := domain.Page[map[string]interface{}]{
+		Data:       make([]map[string]interface{}, 0, len(page.Data)),
+		NextCursor: page.NextCursor,
+	}
+
+	for _, item := range page.Data {
+		raw, err := json.Marshal(item)
+		if err != nil {
+			return projected, err
+		}
+		var all map[string]interface{}
+		if err := json.Unmarshal(raw, &all); err != nil {
+			return projected, err
+		}
+
+		selected := make(map[string]interface{}, len(fields))
+		for _, field := range fields {
+			if value, ok := all[field]; ok {
+				selected[field] = value
+			}
+		}
+		projected.Data = append(projected.Data, selected)
+	}
+	return projected, nil
+}
@@ -0,0 +1,61 @@
+package http
+
+import (
+	"net/http"
+
+	"notes-app/internal/domain"
+
+	"github.com/gin-gonic/gin"
+)
+
+type PasswordResetHandler struct {
+	passwordUsecase domain.PasswordUsecase
+}
+
+func NewPasswordResetHandler(r *gin.RouterGroup, pu domain.PasswordUsecase) {
+	handler := &PasswordResetHandler{
+		passwordUsecase: pu,
+	}
+
+	r.POST("/password/forgot", handler.Forgot)
+	r.POST("/password/reset", handler.Reset)
+}
+
+// Forgot mails a reset link to the account's email address. It answers the same
+// whether or not the account exists.
+func (h *PasswordResetHandler) Forgot(c *gin.Context) {
+	var req struct {
+		Username string `json:"username" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.passwordUsecase.RequestReset(req.Username); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{"message": "If the account has an email address, a reset link was sent to it"})
+}
+
+func (h *PasswordResetHandler) Reset(c *gin.Context) {
+	var req struct {
+		Token       string `json:"token" binding:"required"`
+		NewPassword string `json:"new_password" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.passwordUsecase.ResetPassword(req.Token, req.NewPassword); err != nil {
+		c.JSON(passwordErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Password reset, please log in again"})
+}
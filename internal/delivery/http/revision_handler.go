@@ -0,0 +1,128 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+
+	"notes-app/internal/delivery/http/middleware"
+	"notes-app/internal/domain"
+
+	"github.com/gin-gonic/gin"
+)
+
+type RevisionHandler struct {
+	noteUsecase domain.NoteUsecase
+}
+
+func NewRevisionHandler(r *gin.RouterGroup, nu domain.NoteUsecase) {
+	handler := &RevisionHandler{
+		noteUsecase: nu,
+	}
+
+	read := middleware.RequireScope(domain.ScopeNotesRead)
+	write := middleware.RequireScope(domain.ScopeNotesWrite)
+	r.GET("/notes/:id/revisions", read, handler.GetAll)
+	r.GET("/notes/:id/revisions/diff", read, handler.Diff)
+	r.GET("/notes/:id/revisions/:rev", read, handler.GetByRevision)
+	r.POST("/notes/:id/revisions/:rev/restore", write, handler.Restore)
+}
+
+func (h *RevisionHandler) GetAll(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return
+	}
+
+	user, _ := c.Get("user")
+	userObj := user.(*domain.User)
+
+	page, err := h.noteUsecase.GetRevisions(uint(id), userObj, pageRequest(c))
+	if err != nil {
+		c.JSON(noteErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+}
+
+func (h *RevisionHandler) GetByRevision(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return
+	}
+
+	rev, err := strconv.Atoi(c.Param("rev"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid revision"})
+		return
+	}
+
+	user, _ := c.Get("user")
+	userObj := user.(*domain.User)
+
+	revision, err := h.noteUsecase.GetRevision(uint(id), rev, userObj)
+	if err != nil {
+		c.JSON(noteErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, revision)
+}
+
+func (h *RevisionHandler) Diff(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return
+	}
+
+	from, err := strconv.Atoi(c.Query("from"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid from revision"})
+		return
+	}
+	to, err := strconv.Atoi(c.Query("to"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid to revision"})
+		return
+	}
+
+	user, _ := c.Get("user")
+	userObj := user.(*domain.User)
+
+	result, err := h.noteUsecase.DiffRevisions(uint(id), from, to, userObj)
+	if err != nil {
+		c.JSON(noteErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+func (h *RevisionHandler) Restore(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return
+	}
+
+	rev, err := strconv.Atoi(c.Param("rev"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid revision"})
+		return
+	}
+
+	user, _ := c.Get("user")
+	userObj := user.(*domain.User)
+
+	note, err := h.noteUsecase.RestoreRevision(uint(id), rev, userObj)
+	if err != nil {
+		c.JSON(noteErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Header("ETag", noteETag(note))
+	c.JSON(http.StatusOK, note)
+}
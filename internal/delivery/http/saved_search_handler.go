@@ -0,0 +1,170 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"notes-app/internal/delivery/http/middleware"
+	"notes-app/internal/domain"
+
+	"github.com/gin-gonic/gin"
+)
+
+type SavedSearchHandler struct {
+	savedSearchUsecase domain.SavedSearchUsecase
+}
+
+func NewSavedSearchHandler(r *gin.RouterGroup, su domain.SavedSearchUsecase) {
+	handler := &SavedSearchHandler{
+		savedSearchUsecase: su,
+	}
+
+	read := middleware.RequireScope(domain.ScopeNotesRead)
+	write := middleware.RequireScope(domain.ScopeNotesWrite)
+	r.POST("/saved-searches", write, handler.Create)
+	r.GET("/saved-searches", read, handler.GetAll)
+	r.GET("/saved-searches/counts", read, handler.Counts)
+	r.GET("/saved-searches/:id", read, handler.GetByID)
+	r.PUT("/saved-searches/:id", write, handler.Update)
+	r.DELETE("/saved-searches/:id", write, handler.Delete)
+	r.GET("/saved-searches/:id/results", read, handler.Results)
+}
+
+func (h *SavedSearchHandler) Create(c *gin.Context) {
+	var search domain.SavedSearch
+	if err := c.ShouldBindJSON(&search); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, _ := c.Get("user")
+	userObj := user.(*domain.User)
+
+	if err := h.savedSearchUsecase.Create(&search, userObj); err != nil {
+		c.JSON(savedSearchErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, search)
+}
+
+func (h *SavedSearchHandler) GetAll(c *gin.Context) {
+	user, _ := c.Get("user")
+	userObj := user.(*domain.User)
+
+	page, err := h.savedSearchUsecase.GetAll(userObj, pageRequest(c))
+	if err != nil {
+		c.JSON(savedSearchErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+}
+
+func (h *SavedSearchHandler) GetByID(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return
+	}
+
+	user, _ := c.Get("user")
+	userObj := user.(*domain.User)
+
+	search, err := h.savedSearchUsecase.GetByID(uint(id), userObj)
+	if err != nil {
+		c.JSON(savedSearchErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, search)
+}
+
+func (h *SavedSearchHandler) Update(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return
+	}
+
+	var search domain.SavedSearch
+	if err := c.ShouldBindJSON(&search); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, _ := c.Get("user")
+	userObj := user.(*domain.User)
+
+	search.ID = uint(id)
+	if err := h.savedSearchUsecase.Update(&search, userObj); err != nil {
+		c.JSON(savedSearchErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, search)
+}
+
+func (h *SavedSearchHandler) Delete(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return
+	}
+
+	user, _ := c.Get("user")
+	userObj := user.(*domain.User)
+
+	if err := h.savedSearchUsecase.Delete(uint(id), userObj); err != nil {
+		c.JSON(savedSearchErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Saved search deleted successfully"})
+}
+
+func (h *SavedSearchHandler) Results(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return
+	}
+
+	user, _ := c.Get("user")
+	userObj := user.(*domain.User)
+
+	page, err := h.savedSearchUsecase.Results(uint(id), userObj, pageRequest(c))
+	if err != nil {
+		c.JSON(savedSearchErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+}
+
+func (h *SavedSearchHandler) Counts(c *gin.Context) {
+	user, _ := c.Get("user")
+	userObj := user.(*domain.User)
+
+	counts, err := h.savedSearchUsecase.Counts(userObj)
+	if err != nil {
+		c.JSON(savedSearchErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, counts)
+}
+
+func savedSearchErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, domain.ErrSavedSearchNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, domain.ErrSavedSearchNameTaken):
+		return http.StatusConflict
+	case errors.Is(err, domain.ErrSavedSearchInvalid), errors.Is(err, domain.ErrEmptySearchQuery), isPageError(err):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,74 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"notes-app/internal/delivery/http/middleware"
+	"notes-app/internal/domain"
+	"notes-app/pkg/types"
+
+	"github.com/gin-gonic/gin"
+)
+
+type SessionHandler struct {
+	sessionUsecase domain.SessionUsecase
+}
+
+func NewSessionHandler(r *gin.RouterGroup, su domain.SessionUsecase) {
+	handler := &SessionHandler{
+		sessionUsecase: su,
+	}
+
+	account := middleware.RequireScope(domain.ScopeAccount)
+	r.GET("/sessions", account, handler.GetAll)
+	r.DELETE("/sessions/:id", account, handler.Revoke)
+}
+
+func (h *SessionHandler) GetAll(c *gin.Context) {
+	user, _ := c.Get("user")
+	userObj := user.(*domain.User)
+
+	var currentSessionID string
+	if claims, ok := c.Get("claims"); ok {
+		currentSessionID = claims.(*types.UserClaims).SessionID
+	}
+
+	page, err := h.sessionUsecase.GetAll(userObj, currentSessionID, pageRequest(c))
+	if err != nil {
+		c.JSON(sessionErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+}
+
+func (h *SessionHandler) Revoke(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return
+	}
+
+	user, _ := c.Get("user")
+	userObj := user.(*domain.User)
+
+	if err := h.sessionUsecase.Revoke(uint(id), userObj); err != nil {
+		c.JSON(sessionErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Session revoked"})
+}
+
+func sessionErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, domain.ErrSessionNotFound):
+		return http.StatusNotFound
+	case isPageError(err):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
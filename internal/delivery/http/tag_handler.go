@@ -0,0 +1,140 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"notes-app/internal/delivery/http/middleware"
+	"notes-app/internal/domain"
+
+	"github.com/gin-gonic/gin"
+)
+
+type TagHandler struct {
+	tagUsecase domain.TagUsecase
+}
+
+func NewTagHandler(r *gin.RouterGroup, tu domain.TagUsecase) {
+	handler := &TagHandler{
+		tagUsecase: tu,
+	}
+
+	read := middleware.RequireScope(domain.ScopeNotesRead)
+	write := middleware.RequireScope(domain.ScopeNotesWrite)
+	r.POST("/tags", write, handler.Create)
+	r.GET("/tags", read, handler.GetAll)
+	r.GET("/tags/:id", read, handler.GetByID)
+	r.PUT("/tags/:id", write, handler.Update)
+	r.DELETE("/tags/:id", write, handler.Delete)
+}
+
+func (h *TagHandler) Create(c *gin.Context) {
+	var tag domain.Tag
+	if err := c.ShouldBindJSON(&tag); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, _ := c.Get("user")
+	userObj := user.(*domain.User)
+
+	if err := h.tagUsecase.Create(&tag, userObj); err != nil {
+		c.JSON(tagErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, tag)
+}
+
+func (h *TagHandler) GetAll(c *gin.Context) {
+	user, _ := c.Get("user")
+	userObj := user.(*domain.User)
+
+	page, err := h.tagUsecase.GetAll(userObj, pageRequest(c))
+	if err != nil {
+		c.JSON(tagErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+}
+
+func (h *TagHandler) GetByID(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return
+	}
+
+	user, _ := c.Get("user")
+	userObj := user.(*domain.User)
+
+	tag, err := h.tagUsecase.GetByID(uint(id), userObj)
+	if err != nil {
+		c.JSON(tagErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, tag)
+}
+
+func (h *TagHandler) Update(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return
+	}
+
+	var req struct {
+		Name string `json:"name"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, _ := c.Get("user")
+	userObj := user.(*domain.User)
+
+	tag, err := h.tagUsecase.Rename(uint(id), req.Name, userObj)
+	if err != nil {
+		c.JSON(tagErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, tag)
+}
+
+func (h *TagHandler) Delete(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return
+	}
+
+	user, _ := c.Get("user")
+	userObj := user.(*domain.User)
+
+	if err := h.tagUsecase.Delete(uint(id), userObj); err != nil {
+		c.JSON(tagErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Tag deleted successfully"})
+}
+
+func tagErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, domain.ErrTagNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, domain.ErrTagNameTaken):
+		return http.StatusConflict
+	case errors.Is(err, domain.ErrTagNameEmpty):
+		return http.StatusBadRequest
+	case isPageError(err):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,78 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+
+	"notes-app/internal/delivery/http/middleware"
+	"notes-app/internal/domain"
+
+	"github.com/gin-gonic/gin"
+)
+
+type TrashHandler struct {
+	noteUsecase domain.NoteUsecase
+}
+
+func NewTrashHandler(r *gin.RouterGroup, nu domain.NoteUsecase) {
+	handler := &TrashHandler{
+		noteUsecase: nu,
+	}
+
+	read := middleware.RequireScope(domain.ScopeNotesRead)
+	write := middleware.RequireScope(domain.ScopeNotesWrite)
+	r.GET("/trash", read, handler.GetAll)
+	r.POST("/trash/:id/restore", write, handler.Restore)
+	r.DELETE("/trash/:id", write, handler.Purge)
+}
+
+func (h *TrashHandler) GetAll(c *gin.Context) {
+	user, _ := c.Get("user")
+	userObj := user.(*domain.User)
+
+	page, err := h.noteUsecase.GetTrash(userObj, pageRequest(c))
+	if err != nil {
+		c.JSON(noteErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+}
+
+func (h *TrashHandler) Restore(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return
+	}
+
+	user, _ := c.Get("user")
+	userObj := user.(*domain.User)
+
+	note, err := h.noteUsecase.Restore(uint(id), userObj)
+	if err != nil {
+		c.JSON(noteErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Header("ETag", noteETag(note))
+	c.JSON(http.StatusOK, note)
+}
+
+func (h *TrashHandler) Purge(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return
+	}
+
+	user, _ := c.Get("user")
+	userObj := user.(*domain.User)
+
+	if err := h.noteUsecase.Purge(uint(id), userObj); err != nil {
+		c.JSON(noteErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Note permanently deleted"})
+}
@@ -0,0 +1,95 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+
+	"notes-app/internal/delivery/http/middleware"
+	"notes-app/internal/domain"
+
+	"github.com/gin-gonic/gin"
+)
+
+type TwoFactorHandler struct {
+	twoFactorUsecase domain.TwoFactorUsecase
+}
+
+func NewTwoFactorHandler(r *gin.RouterGroup, tu domain.TwoFactorUsecase) {
+	handler := &TwoFactorHandler{
+		twoFactorUsecase: tu,
+	}
+
+	account := middleware.RequireScope(domain.ScopeAccount)
+	r.POST("/2fa/setup", account, handler.Setup)
+	r.POST("/2fa/verify", account, handler.Verify)
+	r.POST("/2fa/disable", account, handler.Disable)
+}
+
+func (h *TwoFactorHandler) Setup(c *gin.Context) {
+	user, _ := c.Get("user")
+	userObj := user.(*domain.User)
+
+	setup, err := h.twoFactorUsecase.Setup(userObj)
+	if err != nil {
+		c.JSON(twoFactorErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, setup)
+}
+
+// Verify enables two-factor authentication with a first code from the app and
+// responds with the recovery codes, which are not shown again.
+func (h *TwoFactorHandler) Verify(c *gin.Context) {
+	var req struct {
+		Code string `json:"code" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, _ := c.Get("user")
+	userObj := user.(*domain.User)
+
+	codes, err := h.twoFactorUsecase.Enable(userObj, req.Code)
+	if err != nil {
+		c.JSON(twoFactorErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"recovery_codes": codes})
+}
+
+func (h *TwoFactorHandler) Disable(c *gin.Context) {
+	var req struct {
+		Code string `json:"code" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, _ := c.Get("user")
+	userObj := user.(*domain.User)
+
+	if err := h.twoFactorUsecase.Disable(userObj, req.Code); err != nil {
+		c.JSON(twoFactorErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Two-factor authentication disabled"})
+}
+
+func twoFactorErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, domain.ErrTwoFactorCodeInvalid):
+		return http.StatusUnauthorized
+	case errors.Is(err, domain.ErrTwoFactorNotSetUp):
+		return http.StatusNotFound
+	case errors.Is(err, domain.ErrTwoFactorAlreadyActive):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,49 @@
+package domain
+
+import (
+	"errors"
+	"notes-app/pkg/types"
+	"time"
+)
+
+// AccessTokenPrefix starts every personal access token, telling them apart
+// from JWTs in the Authorization header.
+const AccessTokenPrefix = "nap_"
+
+var (
+	ErrAccessTokenNotFound = errors.New("access token not found or unauthorized")
+	ErrAccessTokenInvalid  = errors.New("invalid or expired access token")
+	ErrAccessTokenRequest  = errors.New("access token requires a name and at least one scope")
+	ErrAccessTokenExpiry   = errors.New("access token expiry must be in the future")
+)
+
+// PersonalAccessToken is a long-lived credential for scripts. Only the hash of
+// the token is stored; Token is filled once, in the response to its creation.
+// Prefix keeps the first characters so users can recognise their tokens.
+type PersonalAccessToken struct {
+	ID         uint       `json:"id" gorm:"primaryKey"`
+	UserID     uint       `json:"user_id" gorm:"not null;index"`
+	Name       string     `json:"name" gorm:"not null"`
+	Prefix     string     `json:"prefix" gorm:"type:varchar(16);not null"`
+	TokenHash  string     `json:"-" gorm:"type:varchar(64);not null;uniqueIndex"`
+	Scopes     []string   `json:"scopes" gorm:"serializer:json;not null"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+	Token      string     `json:"token,omitempty" gorm:"-"`
+}
+
+type PersonalAccessTokenRepository interface {
+	Create(token *PersonalAccessToken) error
+	GetByHash(hash string) (*PersonalAccessToken, error)
+	GetAllByUserID(userID uint, page PageRequest) (Page[PersonalAccessToken], error)
+	Delete(id, userID uint) error
+	Touch(id uint, usedAt time.Time) error
+}
+
+type PersonalAccessTokenUsecase interface {
+	Create(token *PersonalAccessToken, user *User, callerScopes []string) error
+	GetAll(user *User, page PageRequest) (Page[PersonalAccessToken], error)
+	Revoke(id uint, user *User) error
+	Authenticate(token string) (*User, *types.UserClaims, error)
+}
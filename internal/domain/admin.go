@@ -0,0 +1,11 @@
+package domain
+
+// AdminUsecase serves the /admin API. Every method expects the caller to have
+// been authorised as an administrator already.
+type AdminUsecase interface {
+	ListUsers(query string, page PageRequest) (Page[UserSummary], error)
+	GetUser(id uint) (*UserSummary, error)
+	SetDisabled(id uint, disabled bool, admin *User) (*UserSummary, error)
+	ResetPassword(id uint) (string, error)
+	Unlock(id uint) error
+}
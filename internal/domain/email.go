@@ -0,0 +1,46 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrInvalidEmail             = errors.New("invalid email address")
+	ErrEmailVerificationInvalid = errors.New("invalid or expired email verification token")
+)
+
+// EmailVerificationToken is a single-use token mailed to an address a user wants
+// to use. The address only becomes the user's email, and so a place to send
+// password resets, once the token comes back. Only its hash is kept.
+type EmailVerificationToken struct {
+	ID        uint      `gorm:"primaryKey"`
+	UserID    uint      `gorm:"not null;index"`
+	Email     string    `gorm:"type:varchar(255);not null"`
+	TokenHash string    `gorm:"type:varchar(64);not null;uniqueIndex"`
+	ExpiresAt time.Time `gorm:"not null"`
+	UsedAt    *time.Time
+	CreatedAt time.Time
+}
+
+// EmailVerificationRepository stores verification tokens. Use spends an
+// unexpired token and returns it, failing with ErrEmailVerificationInvalid when
+// there is none; it must be atomic so a token works only once.
+type EmailVerificationRepository interface {
+	Create(token *EmailVerificationToken) error
+	Use(tokenHash string) (*EmailVerificationToken, error)
+	InvalidateAllByUserID(userID uint) error
+}
+
+// EmailVerification configures the verification mails the way PasswordReset
+// configures reset mails.
+type EmailVerification struct {
+	TTL time.Duration
+	URL string
+}
+
+type EmailUsecase interface {
+	ChangeEmail(user *User, email, currentPassword string) error
+	SendVerification(user *User, email string) error
+	VerifyEmail(token string) error
+}
@@ -0,0 +1,92 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+// FilterExpr is a node of a parsed structured filter such as
+// `status:todo AND updated_at>2026-01-01 AND title:~"meeting"`.
+type FilterExpr interface {
+	filterExpr()
+}
+
+type FilterAnd struct {
+	Left, Right FilterExpr
+}
+
+type FilterOr struct {
+	Left, Right FilterExpr
+}
+
+type FilterNot struct {
+	Expr FilterExpr
+}
+
+// Filter comparison operators.
+const (
+	FilterEq       = ":"
+	FilterContains = ":~"
+	FilterNotEq    = "!="
+	FilterGt       = ">"
+	FilterGte      = ">="
+	FilterLt       = "<"
+	FilterLte      = "<="
+)
+
+// FilterCondition compares one field with a value. Value holds a string, an
+// int, a NoteStatus or a FilterTimeRange depending on the field's FilterFieldType.
+type FilterCondition struct {
+	Field string
+	Op    string
+	Value interface{}
+}
+
+// FilterTimeRange is the value of a time condition. A bare date covers the
+// whole day [Start, End); a full timestamp has Start == End.
+type FilterTimeRange struct {
+	Start time.Time
+	End   time.Time
+}
+
+func (FilterAnd) filterExpr()       {}
+func (FilterOr) filterExpr()        {}
+func (FilterNot) filterExpr()       {}
+func (FilterCondition) filterExpr() {}
+
+type FilterFieldType int
+
+const (
+	FilterString FilterFieldType = iota
+	FilterInt
+	FilterTime
+	FilterStatus
+	// FilterLegacyIsDone is the deprecated is_done field. It takes the same
+	// values as the is_done request field and is rewritten into a status condition.
+	FilterLegacyIsDone
+)
+
+// NoteFilterFields lists the fields that may appear in a note filter expression.
+var NoteFilterFields = map[string]FilterFieldType{
+	"title":        FilterString,
+	"content":      FilterString,
+	"status":       FilterStatus,
+	"is_done":      FilterLegacyIsDone,
+	"tag":          FilterString,
+	"notebook_id":  FilterInt,
+	"version":      FilterInt,
+	"created_at":   FilterTime,
+	"updated_at":   FilterTime,
+	"completed_at": FilterTime,
+}
+
+// FilterSyntaxError reports where a filter expression failed to parse.
+// Position is the 1-based character offset into the expression.
+type FilterSyntaxError struct {
+	Position int
+	Message  string
+}
+
+func (e *FilterSyntaxError) Error() string {
+	return fmt.Sprintf("invalid filter at position %d: %s", e.Position, e.Message)
+}
@@ -0,0 +1,114 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	LoginKeyUsername = "user"
+	LoginKeyIP       = "ip"
+)
+
+// LoginAttemptKey names what failed logins are counted against: a username or
+// a client IP address.
+type LoginAttemptKey struct {
+	Kind  string
+	Value string
+}
+
+func UsernameLoginKey(username string) LoginAttemptKey {
+	return LoginAttemptKey{Kind: LoginKeyUsername, Value: strings.ToLower(strings.TrimSpace(username))}
+}
+
+func IPLoginKey(ip string) LoginAttemptKey {
+	return LoginAttemptKey{Kind: LoginKeyIP, Value: ip}
+}
+
+func (k LoginAttemptKey) String() string {
+	return k.Kind + ":" + k.Value
+}
+
+// LoginThrottledError rejects a login attempt made too soon after failed ones.
+// Locked means the threshold was reached and the account (or IP) is locked out
+// rather than just backing off.
+type LoginThrottledError struct {
+	RetryAfter time.Duration
+	Locked     bool
+}
+
+func (e *LoginThrottledError) Error() string {
+	if e.Locked {
+		return fmt.Sprintf("too many failed login attempts, locked for %s", e.RetryAfter.Round(time.Second))
+	}
+	return fmt.Sprintf("too many failed login attempts, retry in %s", e.RetryAfter.Round(time.Second))
+}
+
+// LoginAttempt is the failure record of one key. BlockedUntil is when the next
+// attempt is allowed.
+type LoginAttempt struct {
+	Key           string `gorm:"primaryKey;type:varchar(300)"`
+	Failures      int    `gorm:"not null;default:0"`
+	LastFailureAt time.Time
+	BlockedUntil  time.Time
+	Locked        bool `gorm:"not null;default:false"`
+}
+
+// LoginAttemptPolicy doubles the wait after every consecutive failure, from
+// BackoffBase up to BackoffMax, and locks the key for LockoutDuration once its
+// threshold is reached. Failures are forgotten after LockoutDuration without
+// one. A threshold of 0 disables lockout for that kind of key.
+type LoginAttemptPolicy struct {
+	UserThreshold   int
+	IPThreshold     int
+	LockoutDuration time.Duration
+	BackoffBase     time.Duration
+	BackoffMax      time.Duration
+}
+
+// Fail records a failed attempt of kind at now on attempt.
+func (p LoginAttemptPolicy) Fail(attempt *LoginAttempt, kind string, now time.Time) {
+	if !attempt.LastFailureAt.IsZero() && now.Sub(attempt.LastFailureAt) > p.LockoutDuration {
+		attempt.Failures = 0
+	}
+	attempt.Failures++
+	attempt.LastFailureAt = now
+
+	threshold := p.UserThreshold
+	if kind == LoginKeyIP {
+		threshold = p.IPThreshold
+	}
+	if threshold > 0 && attempt.Failures >= threshold {
+		attempt.Locked = true
+		attempt.BlockedUntil = now.Add(p.LockoutDuration)
+		return
+	}
+
+	delay := p.BackoffBase
+	for i := 1; i < attempt.Failures && delay < p.BackoffMax; i++ {
+		delay *= 2
+	}
+	if delay > p.BackoffMax {
+		delay = p.BackoffMax
+	}
+	attempt.Locked = false
+	attempt.BlockedUntil = now.Add(delay)
+}
+
+// Throttle returns the error for an attempt at now, or nil when it is allowed.
+func (p LoginAttemptPolicy) Throttle(attempt *LoginAttempt, now time.Time) error {
+	if attempt == nil || !now.Before(attempt.BlockedUntil) {
+		return nil
+	}
+	return &LoginThrottledError{RetryAfter: attempt.BlockedUntil.Sub(now), Locked: attempt.Locked}
+}
+
+// LoginAttemptTracker counts failed logins. Check returns a
+// *LoginThrottledError while key is blocked. Implementations shared by several
+// app instances must apply RecordFailure atomically.
+type LoginAttemptTracker interface {
+	Check(key LoginAttemptKey) error
+	RecordFailure(key LoginAttemptKey) error
+	Reset(key LoginAttemptKey) error
+}
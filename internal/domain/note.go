@@ -1,31 +1,123 @@
 package domain
 
-import "time"
+import (
+	"errors"
+	"time"
 
+	"gorm.io/gorm"
+)
+
+var (
+	ErrNoteNotFound     = errors.New("note not found or unauthorized")
+	ErrVersionMismatch  = errors.New("note has been modified since it was last read")
+	ErrEmptySearchQuery = errors.New("search query is required")
+)
+
+// Note is soft deleted: DeletedAt is set while it sits in the trash. Version is
+// incremented on every content change and used for optimistic locking.
+// IsDone is the deprecated boolean-ish predecessor of Status; it is only read
+// from requests and never stored.
 type Note struct {
-	ID        uint      `json:"id" gorm:"primaryKey"`
-	UserID    uint      `json:"user_id"`
-	NoteTitle string    `json:"note_title" gorm:"not null"`
-	Content   string    `json:"content"`
-	IsDone    string    `json:"is_done"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID          uint           `json:"id" gorm:"primaryKey"`
+	UserID      uint           `json:"user_id"`
+	NotebookID  *uint          `json:"notebook_id" gorm:"index"`
+	NoteTitle   string         `json:"note_title" gorm:"not null"`
+	Content     string         `json:"content"`
+	Status      NoteStatus     `json:"status" gorm:"type:varchar(20);not null;default:todo;index"`
+	CompletedAt *time.Time     `json:"completed_at"`
+	IsDone      string         `json:"is_done,omitempty" gorm:"-"`
+	Tags        []Tag          `json:"tags,omitempty" gorm:"many2many:note_tags;"`
+	Version     int            `json:"version" gorm:"not null;default:1"`
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+	DeletedAt   gorm.DeletedAt `json:"deleted_at,omitempty" gorm:"index"`
+}
+
+// NoteFilter narrows the notes returned by GetAllByUserID.
+// With MatchAllTags a note must carry every tag in Tags, otherwise any one is enough.
+// NotebookID restricts the listing to notes filed directly in that notebook.
+// Expression is a structured filter (see usecase.ParseNoteFilter) which the
+// usecase parses into Expr before it reaches the repository.
+type NoteFilter struct {
+	Tags         []string
+	MatchAllTags bool
+	NotebookID   *uint
+	Expression   string
+	Expr         FilterExpr
+}
+
+// Sortable note fields for NoteListOptions.SortBy. NoteSortRank only applies
+// to search results, where it is the default.
+const (
+	NoteSortCreatedAt = "created_at"
+	NoteSortUpdatedAt = "updated_at"
+	NoteSortTitle     = "title"
+	NoteSortRank      = "rank"
+)
+
+// NoteFields lists the JSON fields a client may select with NoteListOptions.Fields.
+var NoteFields = []string{
+	"id", "user_id", "notebook_id", "note_title", "content", "status",
+	"completed_at", "tags", "version", "created_at", "updated_at",
+}
+
+// NoteListOptions controls ordering, paging and projection of note listings.
+// An empty Fields selects every field.
+type NoteListOptions struct {
+	Page       PageRequest
+	SortBy     string
+	Descending bool
+	Fields     []string
+}
+
+// NoteSearchResult is a full-text search hit. Rank orders results (title matches
+// weigh more than content matches) and Snippet highlights the matched terms.
+type NoteSearchResult struct {
+	Note
+	Rank    float64 `json:"rank"`
+	Snippet string  `json:"snippet"`
 }
 
+// NoteRepository.Update and Delete treat a non-zero Version / version argument as
+// the version the caller last read and fail with ErrVersionMismatch when it is stale.
 type NoteRepository interface {
 	Create(note *Note) error
 	GetByID(id, userID uint) (*Note, error)
-	GetAllByUserID(userID uint) ([]Note, error)
+	GetAllByUserID(userID uint, filter NoteFilter, opts NoteListOptions) (Page[Note], error)
 	Update(note *Note, userID uint) error
-	Delete(id, userID uint) error
-	Query(query string, userID uint) ([]Note, error)
+	Delete(id, userID uint, version int) error
+	Query(query string, userID uint, opts NoteListOptions) (Page[NoteSearchResult], error)
+	CountQueries(queries []string, userID uint) ([]int64, error)
+	AttachTags(noteID, userID uint, tagIDs []uint) error
+	DetachTag(noteID, userID, tagID uint) error
+	Move(noteID, userID uint, notebookID *uint) error
+	GetTrashByUserID(userID uint, page PageRequest) (Page[Note], error)
+	Restore(id, userID uint) error
+	Purge(id, userID uint) error
+	PurgeDeletedBefore(cutoff time.Time) (int64, error)
+	GetRevisions(noteID, userID uint, page PageRequest) (Page[NoteRevision], error)
+	GetRevision(noteID, userID uint, revision int) (*NoteRevision, error)
+	RestoreRevision(noteID, userID uint, revision int) error
+	PruneRevisions(noteID uint, retention RevisionRetention) error
 }
 
 type NoteUsecase interface {
 	Create(note *Note, user *User) error
 	GetByID(id uint, user *User) (*Note, error)
-	GetAll(user *User) ([]Note, error)
+	GetAll(user *User, filter NoteFilter, opts NoteListOptions) (Page[Note], error)
 	Update(note *Note, user *User) error
-	Delete(id uint, user *User) error
-	Query(query string, user *User) ([]Note, error)
+	Delete(id uint, version int, user *User) error
+	Query(query string, user *User, opts NoteListOptions) (Page[NoteSearchResult], error)
+	CountQueries(queries []string, user *User) ([]int64, error)
+	AttachTags(noteID uint, tagIDs []uint, user *User) (*Note, error)
+	DetachTag(noteID, tagID uint, user *User) (*Note, error)
+	Move(noteID uint, notebookID *uint, user *User) (*Note, error)
+	GetTrash(user *User, page PageRequest) (Page[Note], error)
+	Restore(id uint, user *User) (*Note, error)
+	Purge(id uint, user *User) error
+	GetRevisions(noteID uint, user *User, page PageRequest) (Page[NoteRevision], error)
+	GetRevision(noteID uint, revision int, user *User) (*NoteRevision, error)
+	DiffRevisions(noteID uint, from, to int, user *User) (*RevisionDiff, error)
+	RestoreRevision(noteID uint, revision int, user *User) (*Note, error)
+	Transition(id uint, status NoteStatus, version int, user *User) (*Note, error)
 }
@@ -0,0 +1,38 @@
+package domain
+
+import (
+	"errors"
+	"time"
+
+	"notes-app/pkg/diff"
+)
+
+var ErrRevisionNotFound = errors.New("revision not found or unauthorized")
+
+// NoteRevision is a snapshot of a note taken every time it is created, updated or restored.
+type NoteRevision struct {
+	ID        uint       `json:"id" gorm:"primaryKey"`
+	NoteID    uint       `json:"note_id" gorm:"not null;uniqueIndex:idx_note_revisions_note_revision"`
+	UserID    uint       `json:"user_id" gorm:"not null;index"`
+	Revision  int        `json:"revision" gorm:"not null;uniqueIndex:idx_note_revisions_note_revision"`
+	NoteTitle string     `json:"note_title"`
+	Content   string     `json:"content"`
+	Status    NoteStatus `json:"status" gorm:"type:varchar(20)"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// RevisionRetention limits how many revisions are kept per note. Each non-zero
+// limit prunes independently and the latest revision is always kept.
+type RevisionRetention struct {
+	KeepLast int
+	KeepFor  time.Duration
+}
+
+type RevisionDiff struct {
+	NoteID    uint        `json:"note_id"`
+	From      int         `json:"from"`
+	To        int         `json:"to"`
+	NoteTitle []diff.Line `json:"note_title"`
+	Content   []diff.Line `json:"content"`
+	Status    []diff.Line `json:"status"`
+}
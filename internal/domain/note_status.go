@@ -0,0 +1,92 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	ErrInvalidStatus        = errors.New("invalid status, expected one of todo, in_progress, done, archived")
+	ErrTransitionNotAllowed = errors.New("status transition not allowed")
+)
+
+type NoteStatus string
+
+const (
+	NoteStatusTodo       NoteStatus = "todo"
+	NoteStatusInProgress NoteStatus = "in_progress"
+	NoteStatusDone       NoteStatus = "done"
+	NoteStatusArchived   NoteStatus = "archived"
+)
+
+func (s NoteStatus) Valid() bool {
+	switch s {
+	case NoteStatusTodo, NoteStatusInProgress, NoteStatusDone, NoteStatusArchived:
+		return true
+	}
+	return false
+}
+
+// NoteTransitions maps each status to the statuses a note may move to from it.
+type NoteTransitions map[NoteStatus][]NoteStatus
+
+// DefaultNoteTransitions lets notes move freely between the active statuses,
+// while an archived note has to be reopened before anything else.
+var DefaultNoteTransitions = NoteTransitions{
+	NoteStatusTodo:       {NoteStatusInProgress, NoteStatusDone, NoteStatusArchived},
+	NoteStatusInProgress: {NoteStatusTodo, NoteStatusDone, NoteStatusArchived},
+	NoteStatusDone:       {NoteStatusTodo, NoteStatusInProgress, NoteStatusArchived},
+	NoteStatusArchived:   {NoteStatusTodo},
+}
+
+func (t NoteTransitions) Allows(from, to NoteStatus) bool {
+	if from == to {
+		return true
+	}
+	for _, allowed := range t[from] {
+		if allowed == to {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseNoteTransitions reads a transition table written as
+// "todo:in_progress|done;in_progress:done;done:todo". Statuses missing from the
+// spec have no outgoing transitions.
+func ParseNoteTransitions(spec string) (NoteTransitions, error) {
+	transitions := NoteTransitions{}
+	for _, rule := range strings.Split(spec, ";") {
+		rule = strings.TrimSpace(rule)
+		if rule == "" {
+			continue
+		}
+
+		from, targets, found := strings.Cut(rule, ":")
+		if !found || !NoteStatus(strings.TrimSpace(from)).Valid() {
+			return nil, fmt.Errorf("invalid transition rule %q", rule)
+		}
+		for _, to := range strings.Split(targets, "|") {
+			status := NoteStatus(strings.TrimSpace(to))
+			if !status.Valid() {
+				return nil, fmt.Errorf("invalid status %q in transition rule %q", to, rule)
+			}
+			fromStatus := NoteStatus(strings.TrimSpace(from))
+			transitions[fromStatus] = append(transitions[fromStatus], status)
+		}
+	}
+	return transitions, nil
+}
+
+// StatusFromLegacyIsDone maps the free-form is_done strings accepted before the
+// status field existed. It reports false for values it cannot interpret.
+func StatusFromLegacyIsDone(isDone string) (NoteStatus, bool) {
+	switch strings.ToLower(strings.TrimSpace(isDone)) {
+	case "true", "t", "yes", "y", "1", "done", "completed":
+		return NoteStatusDone, true
+	case "false", "f", "no", "n", "0", "", "todo", "pending":
+		return NoteStatusTodo, true
+	}
+	return "", false
+}
@@ -0,0 +1,55 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrNotebookNotFound  = errors.New("notebook not found or unauthorized")
+	ErrNotebookCycle     = errors.New("notebook cannot be moved into itself or one of its descendants")
+	ErrNotebookNameEmpty = errors.New("notebook name is required")
+	ErrInvalidDeleteMode = errors.New("delete mode must be either cascade or rehome")
+)
+
+// Delete modes for a notebook: cascade removes the whole subtree including its
+// notes, rehome moves child notebooks and notes up to the deleted notebook's parent.
+const (
+	NotebookDeleteCascade = "cascade"
+	NotebookDeleteRehome  = "rehome"
+)
+
+type Notebook struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	UserID    uint      `json:"user_id" gorm:"not null;index"`
+	ParentID  *uint     `json:"parent_id" gorm:"index"`
+	Name      string    `json:"name" gorm:"not null"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// NotebookTree is a notebook together with its nested notebooks and the notes filed directly in it.
+type NotebookTree struct {
+	Notebook
+	Children []NotebookTree `json:"children"`
+	Notes    []Note         `json:"notes"`
+}
+
+type NotebookRepository interface {
+	Create(notebook *Notebook) error
+	GetByID(id, userID uint) (*Notebook, error)
+	GetAllByUserID(userID uint, page PageRequest) (Page[Notebook], error)
+	GetSubtree(id, userID uint) ([]Notebook, []Note, error)
+	Rename(id, userID uint, name string) error
+	Move(id, userID uint, parentID *uint) error
+	Delete(id, userID uint, mode string) error
+}
+
+type NotebookUsecase interface {
+	Create(notebook *Notebook, user *User) error
+	GetAll(user *User, page PageRequest) (Page[Notebook], error)
+	GetTree(id uint, user *User) (*NotebookTree, error)
+	Rename(id uint, name string, user *User) (*Notebook, error)
+	Move(id uint, parentID *uint, user *User) (*Notebook, error)
+	Delete(id uint, mode string, user *User) error
+}
@@ -0,0 +1,43 @@
+package domain
+
+import (
+	"errors"
+
+	"notes-app/pkg/pagination"
+)
+
+const (
+	DefaultPageLimit = 20
+	MaxPageLimit     = 100
+)
+
+var (
+	ErrInvalidCursor = pagination.ErrInvalidCursor
+	ErrInvalidSort   = errors.New("invalid sort field or order")
+	ErrUnknownField  = errors.New("unknown field")
+)
+
+// PageRequest asks for up to Limit items following the opaque Cursor returned
+// as NextCursor by the previous page. An empty Cursor starts from the beginning.
+type PageRequest struct {
+	Limit  int
+	Cursor string
+}
+
+// Normalized clamps Limit into [1, MaxPageLimit], defaulting to DefaultPageLimit.
+func (p PageRequest) Normalized() PageRequest {
+	if p.Limit < 1 {
+		p.Limit = DefaultPageLimit
+	}
+	if p.Limit > MaxPageLimit {
+		p.Limit = MaxPageLimit
+	}
+	return p
+}
+
+// Page is the response envelope shared by every list endpoint. NextCursor is
+// empty on the last page.
+type Page[T any] struct {
+	Data       []T    `json:"data"`
+	NextCursor string `json:"next_cursor,omitempty"`
+}
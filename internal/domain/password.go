@@ -0,0 +1,81 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+var (
+	ErrWeakPassword         = errors.New("password does not meet the password policy")
+	ErrWrongPassword        = errors.New("current password is incorrect")
+	ErrPasswordResetInvalid = errors.New("invalid or expired password reset token")
+)
+
+// PasswordPolicy is checked whenever a user chooses a password. Blocklisted
+// passwords are compared case-insensitively.
+type PasswordPolicy struct {
+	MinLength int
+	blocklist map[string]struct{}
+}
+
+func NewPasswordPolicy(minLength int, blocklist []string) PasswordPolicy {
+	policy := PasswordPolicy{MinLength: minLength, blocklist: make(map[string]struct{}, len(blocklist))}
+	for _, password := range blocklist {
+		policy.blocklist[strings.ToLower(password)] = struct{}{}
+	}
+	return policy
+}
+
+func (p PasswordPolicy) Validate(password string) error {
+	if len([]rune(password)) < p.MinLength {
+		return fmt.Errorf("%w: it must be at least %d characters long", ErrWeakPassword, p.MinLength)
+	}
+	if _, blocked := p.blocklist[strings.ToLower(password)]; blocked {
+		return fmt.Errorf("%w: it is too common", ErrWeakPassword)
+	}
+	return nil
+}
+
+// PasswordHasher hashes passwords into strings that name their algorithm and
+// parameters. NeedsRehash reports a hash made under outdated settings, which is
+// replaced the next time the user logs in.
+type PasswordHasher interface {
+	Hash(password string) (string, error)
+	Verify(password, encoded string) (bool, error)
+	NeedsRehash(encoded string) bool
+}
+
+// PasswordResetToken is a single-use token mailed to a user who forgot their
+// password. Only its hash is kept.
+type PasswordResetToken struct {
+	ID        uint      `gorm:"primaryKey"`
+	UserID    uint      `gorm:"not null;index"`
+	TokenHash string    `gorm:"type:varchar(64);not null;uniqueIndex"`
+	ExpiresAt time.Time `gorm:"not null"`
+	UsedAt    *time.Time
+	CreatedAt time.Time
+}
+
+// PasswordResetRepository stores reset tokens. Use spends an unexpired token and
+// returns it, failing with ErrPasswordResetInvalid when there is none; it must
+// be atomic so a token works only once.
+type PasswordResetRepository interface {
+	Create(token *PasswordResetToken) error
+	Use(tokenHash string) (*PasswordResetToken, error)
+	InvalidateAllByUserID(userID uint) error
+}
+
+// PasswordReset configures the reset mails: tokens expire after TTL. When URL is
+// set, the mail also links to URL followed by the token, for a frontend page.
+type PasswordReset struct {
+	TTL time.Duration
+	URL string
+}
+
+type PasswordUsecase interface {
+	ChangePassword(user *User, currentPassword, newPassword, currentSessionID string) error
+	RequestReset(username string) error
+	ResetPassword(token, newPassword string) error
+}
@@ -0,0 +1,39 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrRefreshTokenInvalid = errors.New("invalid refresh token")
+	ErrRefreshTokenReused  = errors.New("refresh token reuse detected, all sessions of this login were revoked")
+)
+
+// RefreshToken is the server side record of an issued refresh token. Only the
+// hash of the token is kept. Every token rotated from the same login shares a
+// FamilyID; UsedAt is set when the token is exchanged, so presenting it again
+// reveals a replay.
+type RefreshToken struct {
+	ID        uint       `json:"id" gorm:"primaryKey"`
+	UserID    uint       `json:"user_id" gorm:"not null;index"`
+	JTI       string     `json:"jti" gorm:"column:jti;type:varchar(64);not null;uniqueIndex"`
+	FamilyID  string     `json:"family_id" gorm:"type:varchar(64);not null;index"`
+	TokenHash string     `json:"-" gorm:"type:varchar(64);not null"`
+	ExpiresAt time.Time  `json:"expires_at" gorm:"not null"`
+	UsedAt    *time.Time `json:"used_at"`
+	RevokedAt *time.Time `json:"revoked_at"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// RefreshTokenRepository stores refresh tokens and answers revocation checks.
+// Use marks a token as exchanged and fails with ErrRefreshTokenReused when it
+// was already used or revoked, which must be atomic across app instances.
+type RefreshTokenRepository interface {
+	Create(token *RefreshToken) error
+	GetByJTI(jti string) (*RefreshToken, error)
+	Use(jti string) error
+	RevokeFamily(familyID string) error
+	RevokeAllByUserID(userID uint) error
+	RevokeOthers(userID uint, keepFamilyID string) error
+}
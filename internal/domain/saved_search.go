@@ -0,0 +1,51 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrSavedSearchNotFound  = errors.New("saved search not found or unauthorized")
+	ErrSavedSearchNameTaken = errors.New("saved search name already exists")
+	ErrSavedSearchInvalid   = errors.New("saved search requires a name and a query")
+)
+
+// SavedSearch is a named full-text query a user runs repeatedly. Sort is a note
+// sort field optionally followed by ":asc" or ":desc", e.g. "updated_at:desc";
+// empty means ranked by relevance.
+type SavedSearch struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	UserID    uint      `json:"user_id" gorm:"not null;uniqueIndex:idx_saved_searches_user_name"`
+	Name      string    `json:"name" gorm:"not null;uniqueIndex:idx_saved_searches_user_name"`
+	Query     string    `json:"query" gorm:"not null"`
+	Sort      string    `json:"sort"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type SavedSearchCount struct {
+	ID    uint   `json:"id"`
+	Name  string `json:"name"`
+	Count int64  `json:"count"`
+}
+
+type SavedSearchRepository interface {
+	Create(search *SavedSearch) error
+	GetByID(id, userID uint) (*SavedSearch, error)
+	GetByName(name string, userID uint) (*SavedSearch, error)
+	GetAllByUserID(userID uint, page PageRequest) (Page[SavedSearch], error)
+	ListByUserID(userID uint) ([]SavedSearch, error)
+	Update(search *SavedSearch, userID uint) error
+	Delete(id, userID uint) error
+}
+
+type SavedSearchUsecase interface {
+	Create(search *SavedSearch, user *User) error
+	GetByID(id uint, user *User) (*SavedSearch, error)
+	GetAll(user *User, page PageRequest) (Page[SavedSearch], error)
+	Update(search *SavedSearch, user *User) error
+	Delete(id uint, user *User) error
+	Results(id uint, user *User, page PageRequest) (Page[NoteSearchResult], error)
+	Counts(user *User) ([]SavedSearchCount, error)
+}
@@ -0,0 +1,36 @@
+package domain
+
+import "errors"
+
+var (
+	ErrInvalidScope      = errors.New("invalid scope")
+	ErrScopeNotGrantable = errors.New("cannot grant a scope the caller does not have")
+)
+
+// Scopes limit what a token may do. Password logins get the scopes of the user;
+// personal access tokens get the subset chosen when they are created. The
+// account scope (tokens, sessions, password and 2FA) is never granted to a
+// personal access token, so a leaked one cannot take over the account.
+const (
+	ScopeNotesRead       = "notes:read"
+	ScopeNotesWrite      = "notes:write"
+	ScopeAdminMigrations = "admin:migrations"
+	ScopeAdminUsers      = "admin:users"
+	ScopeAccount         = "account"
+)
+
+// AllScopes lists every scope a token can be granted; administrators hold all
+// of them, other users only UserScopes.
+var (
+	AllScopes  = []string{ScopeNotesRead, ScopeNotesWrite, ScopeAdminMigrations, ScopeAdminUsers, ScopeAccount}
+	UserScopes = []string{ScopeNotesRead, ScopeNotesWrite, ScopeAccount}
+)
+
+func ValidScope(scope string) bool {
+	for _, s := range AllScopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,48 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrSessionNotFound = errors.New("session not found or unauthorized")
+	ErrSessionRevoked  = errors.New("session has been revoked")
+)
+
+// Session is one login of a user on a device. FamilyID is shared with the
+// refresh tokens rotated from that login and travels in the sid claim of access
+// tokens, so revoking the session ends both. Current is set for the session the
+// request was made with.
+type Session struct {
+	ID         uint       `json:"id" gorm:"primaryKey"`
+	UserID     uint       `json:"user_id" gorm:"not null;index"`
+	FamilyID   string     `json:"-" gorm:"type:varchar(64);not null;uniqueIndex"`
+	UserAgent  string     `json:"user_agent"`
+	IPAddress  string     `json:"ip_address" gorm:"type:varchar(45)"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastUsedAt time.Time  `json:"last_used_at" gorm:"not null"`
+	RevokedAt  *time.Time `json:"-"`
+	Current    bool       `json:"current" gorm:"-"`
+}
+
+// ClientInfo describes the device a login or refresh came from.
+type ClientInfo struct {
+	UserAgent string
+	IPAddress string
+}
+
+type SessionRepository interface {
+	Create(session *Session) error
+	GetByFamilyID(familyID string) (*Session, error)
+	GetActiveByUserID(userID uint, page PageRequest) (Page[Session], error)
+	Touch(id uint, client ClientInfo) error
+	Revoke(id, userID uint) (*Session, error)
+	RevokeAllByUserID(userID uint) error
+	RevokeOthers(userID uint, keepFamilyID string) error
+}
+
+type SessionUsecase interface {
+	GetAll(user *User, currentSessionID string, page PageRequest) (Page[Session], error)
+	Revoke(id uint, user *User) error
+}
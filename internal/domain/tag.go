@@ -0,0 +1,37 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrTagNotFound  = errors.New("tag not found or unauthorized")
+	ErrTagNameTaken = errors.New("tag name already exists")
+	ErrTagNameEmpty = errors.New("tag name is required")
+)
+
+type Tag struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	UserID    uint      `json:"user_id" gorm:"not null;uniqueIndex:idx_tags_user_name"`
+	Name      string    `json:"name" gorm:"not null;uniqueIndex:idx_tags_user_name"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type TagRepository interface {
+	Create(tag *Tag) error
+	GetByID(id, userID uint) (*Tag, error)
+	GetByName(name string, userID uint) (*Tag, error)
+	GetAllByUserID(userID uint, page PageRequest) (Page[Tag], error)
+	Update(tag *Tag, userID uint) error
+	Delete(id, userID uint) error
+}
+
+type TagUsecase interface {
+	Create(tag *Tag, user *User) error
+	GetByID(id uint, user *User) (*Tag, error)
+	GetAll(user *User, page PageRequest) (Page[Tag], error)
+	Rename(id uint, name string, user *User) (*Tag, error)
+	Delete(id uint, user *User) error
+}
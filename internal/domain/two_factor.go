@@ -0,0 +1,58 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrTwoFactorNotSetUp      = errors.New("two-factor authentication has not been set up")
+	ErrTwoFactorAlreadyActive = errors.New("two-factor authentication is already enabled")
+	ErrTwoFactorCodeInvalid   = errors.New("invalid two-factor code")
+	ErrTwoFactorChallenge     = errors.New("invalid or expired two-factor challenge")
+)
+
+// TwoFactor holds a user's TOTP secret. It is pending until EnabledAt is set by
+// verifying a first code. LastUsedStep is the TOTP time step of the last code
+// accepted, so a code cannot be used twice.
+type TwoFactor struct {
+	UserID       uint   `gorm:"primaryKey;autoIncrement:false"`
+	Secret       string `gorm:"type:varchar(64);not null"`
+	EnabledAt    *time.Time
+	LastUsedStep int64 `gorm:"not null;default:0"`
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
+
+// RecoveryCode is a hashed single-use code that stands in for a TOTP code when
+// the user has lost their device.
+type RecoveryCode struct {
+	ID        uint   `gorm:"primaryKey"`
+	UserID    uint   `gorm:"not null;index"`
+	CodeHash  string `gorm:"type:varchar(64);not null"`
+	UsedAt    *time.Time
+	CreatedAt time.Time
+}
+
+// TwoFactorSetup is returned once by Setup for the user to enrol an authenticator app.
+type TwoFactorSetup struct {
+	Secret string `json:"secret"`
+	URI    string `json:"otpauth_uri"`
+}
+
+type TwoFactorRepository interface {
+	Get(userID uint) (*TwoFactor, error)
+	Save(twoFactor *TwoFactor) error
+	Enable(userID uint, step int64, recoveryCodeHashes []string) error
+	Delete(userID uint) error
+	UseStep(userID uint, step int64) error
+	UseRecoveryCode(userID uint, codeHash string) error
+}
+
+type TwoFactorUsecase interface {
+	Setup(user *User) (*TwoFactorSetup, error)
+	Enable(user *User, code string) ([]string, error)
+	Disable(user *User, code string) error
+	IsEnabled(userID uint) (bool, error)
+	Authenticate(userID uint, code string) error
+}
@@ -1,27 +1,81 @@
 package domain
 
 import (
+	"errors"
 	"notes-app/pkg/types"
 	"time"
 )
 
+const (
+	RoleUser  = "user"
+	RoleAdmin = "admin"
+)
+
+var (
+	ErrUserNotFound       = errors.New("user not found")
+	ErrUserDisabled       = errors.New("account is disabled")
+	ErrCannotDisableSelf  = errors.New("administrators cannot disable their own account")
+	ErrInvalidCredentials = errors.New("invalid credentials")
+	ErrUsernameRequired   = errors.New("username is required")
+)
+
+// User is disabled while DisabledAt is set; disabled users cannot log in and
+// their tokens are rejected. Password resets are only mailed to an Email that
+// was verified, i.e. while EmailVerifiedAt is set.
 type User struct {
-	ID        uint      `json:"id" gorm:"primaryKey"`
-	Username  string    `json:"username" gorm:"unique;not null"`
-	Password  string    `json:"password" gorm:"not null"` // "-" means don't show in JSON
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID              uint       `json:"id" gorm:"primaryKey"`
+	Username        string     `json:"username" gorm:"unique;not null"`
+	Password        string     `json:"password" gorm:"not null"` // "-" means don't show in JSON
+	Email           string     `json:"email" gorm:"type:varchar(255)"`
+	EmailVerifiedAt *time.Time `json:"email_verified_at,omitempty"`
+	Role            string     `json:"role" gorm:"type:varchar(20);not null;default:user"`
+	DisabledAt      *time.Time `json:"disabled_at,omitempty"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+}
+
+func (u *User) IsAdmin() bool {
+	return u.Role == RoleAdmin
+}
+
+// UserSummary is the administrator's view of an account, without credentials.
+type UserSummary struct {
+	ID         uint       `json:"id"`
+	Username   string     `json:"username"`
+	Role       string     `json:"role"`
+	DisabledAt *time.Time `json:"disabled_at"`
+	NoteCount  int64      `json:"note_count"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// LoginResult is either a token pair or, for users with two-factor
+// authentication, a challenge to complete with a code at /login/2fa.
+type LoginResult struct {
+	*types.TokenPair
+	TwoFactorRequired bool   `json:"two_factor_required,omitempty"`
+	ChallengeToken    string `json:"challenge_token,omitempty"`
 }
 
 type UserRepository interface {
+	// Create inserts user, making it an admin when it is the first account.
 	Create(user *User) error
 	GetByUsername(username string) (*User, error)
 	GetByID(id uint) (*User, error)
+	Search(query string, page PageRequest) (Page[UserSummary], error)
+	GetSummary(id uint) (*UserSummary, error)
+	SetDisabledAt(id uint, disabledAt *time.Time) error
+	UpdatePassword(id uint, passwordHash string) error
+	SetVerifiedEmail(id uint, email string, verifiedAt time.Time) error
+	PromoteToAdmin(usernames []string) (int64, error)
 }
 
 type UserUsecase interface {
 	Register(user *User) error
-	Login(username, password string) (*types.TokenPair, error)
-	RefreshToken(refreshToken string) (*types.TokenPair, error)
-	ValidateAccessToken(token string) (*User, error)
+	Login(username, password string, client ClientInfo) (*LoginResult, error)
+	LoginTwoFactor(challengeToken, code string, client ClientInfo) (*types.TokenPair, error)
+	RefreshToken(refreshToken string, client ClientInfo) (*types.TokenPair, error)
+	ValidateAccessToken(token string) (*User, *types.UserClaims, error)
+	Logout(refreshToken string) error
+	LogoutAll(user *User) error
+	BootstrapAdmins() error
 }
@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"notes-app/internal/domain"
+
+	"gorm.io/gorm"
+)
+
+// lastUsedResolution bounds how often a token's last_used_at is written, so a
+// busy script does not cause a write per request.
+const lastUsedResolution = time.Minute
+
+type accessTokenRepository struct {
+	db *gorm.DB
+}
+
+func NewAccessTokenRepository(db *gorm.DB) domain.PersonalAccessTokenRepository {
+	return &accessTokenRepository{db}
+}
+
+func (r *accessTokenRepository) Create(token *domain.PersonalAccessToken) error {
+	return r.db.Create(token).Error
+}
+
+func (r *accessTokenRepository) GetByHash(hash string) (*domain.PersonalAccessToken, error) {
+	var token domain.PersonalAccessToken
+	err := r.db.Where("token_hash = ?", hash).First(&token).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrAccessTokenInvalid
+		}
+		return nil, err
+	}
+	return &token, nil
+}
+
+func (r *accessTokenRepository) GetAllByUserID(userID uint, page domain.PageRequest) (domain.Page[domain.PersonalAccessToken], error) {
+	query, err := keyset(r.db.Where("user_id = ?", userID), page, "created_at:desc", "created_at", true, parseTimeCursor)
+	if err != nil {
+		return domain.Page[domain.PersonalAccessToken]{}, err
+	}
+
+	var tokens []domain.PersonalAccessToken
+	if err := query.Find(&tokens).Error; err != nil {
+		return domain.Page[domain.PersonalAccessToken]{}, err
+	}
+	return finishPage(tokens, page, "created_at:desc", func(token domain.PersonalAccessToken) (string, uint) {
+		return formatTimeCursor(token.CreatedAt), token.ID
+	}), nil
+}
+
+func (r *accessTokenRepository) Delete(id, userID uint) error {
+	result := r.db.Where("id = ? AND user_id = ?", id, userID).Delete(&domain.PersonalAccessToken{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrAccessTokenNotFound
+	}
+	return nil
+}
+
+func (r *accessTokenRepository) Touch(id uint, usedAt time.Time) error {
+	return r.db.Model(&domain.PersonalAccessToken{}).
+		Where("id = ? AND (last_used_at IS NULL OR last_used_at < ?)", id, usedAt.Add(-lastUsedResolution)).
+		Update("last_used_at", usedAt).Error
+}
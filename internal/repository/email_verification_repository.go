@@ -0,0 +1,46 @@
+package repository
+
+import (
+	"time"
+
+	"notes-app/internal/domain"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type emailVerificationRepository struct {
+	db *gorm.DB
+}
+
+func NewEmailVerificationRepository(db *gorm.DB) domain.EmailVerificationRepository {
+	return &emailVerificationRepository{db}
+}
+
+func (r *emailVerificationRepository) Create(token *domain.EmailVerificationToken) error {
+	return r.db.Create(token).Error
+}
+
+// Use is a compare-and-swap on used_at like passwordResetRepository.Use.
+func (r *emailVerificationRepository) Use(tokenHash string) (*domain.EmailVerificationToken, error) {
+	now := time.Now()
+	var token domain.EmailVerificationToken
+	result := r.db.Model(&token).Clauses(clause.Returning{}).
+		Where("token_hash = ? AND used_at IS NULL AND expires_at > ?", tokenHash, now).
+		Update("used_at", now)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, domain.ErrEmailVerificationInvalid
+	}
+	return &token, nil
+}
+
+// InvalidateAllByUserID spends the user's outstanding tokens, so only the
+// address asked for last can be verified.
+func (r *emailVerificationRepository) InvalidateAllByUserID(userID uint) error {
+	return r.db.Model(&domain.EmailVerificationToken{}).
+		Where("user_id = ? AND used_at IS NULL", userID).
+		Update("used_at", time.Now()).Error
+}
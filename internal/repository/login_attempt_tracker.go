@@ -0,0 +1,60 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"notes-app/internal/domain"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// loginAttemptTracker keeps failure counts in Postgres so every app instance
+// sees the same counts.
+type loginAttemptTracker struct {
+	db     *gorm.DB
+	policy domain.LoginAttemptPolicy
+}
+
+func NewLoginAttemptTracker(db *gorm.DB, policy domain.LoginAttemptPolicy) domain.LoginAttemptTracker {
+	return &loginAttemptTracker{db: db, policy: policy}
+}
+
+func (t *loginAttemptTracker) Check(key domain.LoginAttemptKey) error {
+	var attempt domain.LoginAttempt
+	err := t.db.Where("key = ?", key.String()).First(&attempt).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return t.policy.Throttle(&attempt, time.Now())
+}
+
+// RecordFailure locks the key's row while applying the policy, so concurrent
+// failures on different instances are all counted.
+func (t *loginAttemptTracker) RecordFailure(key domain.LoginAttemptKey) error {
+	return t.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.OnConflict{DoNothing: true}).
+			Create(&domain.LoginAttempt{Key: key.String()}).Error
+		if err != nil {
+			return err
+		}
+
+		var attempt domain.LoginAttempt
+		err = tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("key = ?", key.String()).First(&attempt).Error
+		if err != nil {
+			return err
+		}
+
+		t.policy.Fail(&attempt, key.Kind, time.Now())
+		return tx.Save(&attempt).Error
+	})
+}
+
+func (t *loginAttemptTracker) Reset(key domain.LoginAttemptKey) error {
+	return t.db.Where("key = ?", key.String()).Delete(&domain.LoginAttempt{}).Error
+}
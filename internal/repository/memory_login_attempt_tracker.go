@@ -0,0 +1,54 @@
+package repository
+
+import (
+	"sync"
+	"time"
+
+	"notes-app/internal/domain"
+)
+
+// memoryLoginAttemptTracker keeps failure counts in process memory. Each app
+// instance counts separately, so it only suits single instance deployments and
+// tests; now is its clock.
+type memoryLoginAttemptTracker struct {
+	mu       sync.Mutex
+	policy   domain.LoginAttemptPolicy
+	now      func() time.Time
+	attempts map[string]*domain.LoginAttempt
+}
+
+func NewMemoryLoginAttemptTracker(policy domain.LoginAttemptPolicy, now func() time.Time) domain.LoginAttemptTracker {
+	return &memoryLoginAttemptTracker{
+		policy:   policy,
+		now:      now,
+		attempts: make(map[string]*domain.LoginAttempt),
+	}
+}
+
+func (t *memoryLoginAttemptTracker) Check(key domain.LoginAttemptKey) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.policy.Throttle(t.attempts[key.String()], t.now())
+}
+
+func (t *memoryLoginAttemptTracker) RecordFailure(key domain.LoginAttemptKey) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	attempt, ok := t.attempts[key.String()]
+	if !ok {
+		attempt = &domain.LoginAttempt{Key: key.String()}
+		t.attempts[key.String()] = attempt
+	}
+	t.policy.Fail(attempt, key.Kind, t.now())
+	return nil
+}
+
+func (t *memoryLoginAttemptTracker) Reset(key domain.LoginAttemptKey) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	delete(t.attempts, key.String())
+	return nil
+}
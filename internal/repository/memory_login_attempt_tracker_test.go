@@ -0,0 +1,172 @@
+package repository
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"notes-app/internal/domain"
+)
+
+var testLoginPolicy = domain.LoginAttemptPolicy{
+	UserThreshold:   3,
+	IPThreshold:     5,
+	LockoutDuration: 15 * time.Minute,
+	BackoffBase:     time.Second,
+	BackoffMax:      8 * time.Second,
+}
+
+// fixedClock is a clock the test moves by hand.
+type fixedClock struct {
+	now time.Time
+}
+
+func (c *fixedClock) Now() time.Time { return c.now }
+
+func (c *fixedClock) Advance(d time.Duration) { c.now = c.now.Add(d) }
+
+func newTestTracker(policy domain.LoginAttemptPolicy) (domain.LoginAttemptTracker, *fixedClock) {
+	clock := &fixedClock{now: time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)}
+	return NewMemoryLoginAttemptTracker(policy, clock.Now), clock
+}
+
+// throttled returns the *LoginThrottledError of err, failing the test when err is
+// something else.
+func throttled(t *testing.T, err error) *domain.LoginThrottledError {
+	t.Helper()
+
+	var throttledErr *domain.LoginThrottledError
+	if !errors.As(err, &throttledErr) {
+		t.Fatalf("got %v, want a *LoginThrottledError", err)
+	}
+	return throttledErr
+}
+
+func TestMemoryLoginAttemptTrackerBackoff(t *testing.T) {
+	policy := testLoginPolicy
+	policy.UserThreshold = 0 // no lockout, so the backoff can reach its cap
+	tracker, _ := newTestTracker(policy)
+	key := domain.UsernameLoginKey("alice")
+
+	if err := tracker.Check(key); err != nil {
+		t.Fatalf("Check before any failure: %v", err)
+	}
+
+	for i, want := range []time.Duration{
+		1 * time.Second,
+		2 * time.Second,
+		4 * time.Second,
+		8 * time.Second,
+		8 * time.Second,
+		8 * time.Second,
+	} {
+		if err := tracker.RecordFailure(key); err != nil {
+			t.Fatal(err)
+		}
+		err := throttled(t, tracker.Check(key))
+		if err.RetryAfter != want {
+			t.Errorf("after %d failures: RetryAfter = %s, want %s", i+1, err.RetryAfter, want)
+		}
+		if err.Locked {
+			t.Errorf("after %d failures: locked, want backing off", i+1)
+		}
+	}
+}
+
+func TestMemoryLoginAttemptTrackerThreshold(t *testing.T) {
+	tests := []struct {
+		name       string
+		key        domain.LoginAttemptKey
+		failures   int
+		locked     bool
+		retryAfter time.Duration
+	}{
+		{"user below threshold", domain.UsernameLoginKey("alice"), 2, false, 2 * time.Second},
+		{"user at threshold", domain.UsernameLoginKey("alice"), 3, true, 15 * time.Minute},
+		{"ip below threshold", domain.IPLoginKey("192.0.2.1"), 4, false, 8 * time.Second},
+		{"ip at threshold", domain.IPLoginKey("192.0.2.1"), 5, true, 15 * time.Minute},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tracker, clock := newTestTracker(testLoginPolicy)
+			for i := 0; i < tt.failures; i++ {
+				if err := tracker.RecordFailure(tt.key); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			// Locked maps to 423 Locked, backing off to 429 Too Many Requests
+			err := throttled(t, tracker.Check(tt.key))
+			if err.Locked != tt.locked {
+				t.Errorf("Locked = %v, want %v", err.Locked, tt.locked)
+			}
+			if err.RetryAfter != tt.retryAfter {
+				t.Errorf("RetryAfter = %s, want %s", err.RetryAfter, tt.retryAfter)
+			}
+
+			clock.Advance(tt.retryAfter)
+			if err := tracker.Check(tt.key); err != nil {
+				t.Errorf("Check once RetryAfter passed: %v", err)
+			}
+		})
+	}
+}
+
+func TestMemoryLoginAttemptTrackerForgetsOldFailures(t *testing.T) {
+	tracker, clock := newTestTracker(testLoginPolicy)
+	key := domain.UsernameLoginKey("alice")
+
+	for i := 0; i < 2; i++ {
+		if err := tracker.RecordFailure(key); err != nil {
+			t.Fatal(err)
+		}
+	}
+	clock.Advance(testLoginPolicy.LockoutDuration + time.Second)
+
+	// Counted from scratch, this is the first failure rather than the third
+	if err := tracker.RecordFailure(key); err != nil {
+		t.Fatal(err)
+	}
+	err := throttled(t, tracker.Check(key))
+	if err.Locked || err.RetryAfter != testLoginPolicy.BackoffBase {
+		t.Errorf("got Locked = %v, RetryAfter = %s; want a first failure's %s backoff",
+			err.Locked, err.RetryAfter, testLoginPolicy.BackoffBase)
+	}
+}
+
+func TestMemoryLoginAttemptTrackerReset(t *testing.T) {
+	tracker, _ := newTestTracker(testLoginPolicy)
+	user := domain.UsernameLoginKey("alice")
+	ip := domain.IPLoginKey("192.0.2.1")
+
+	for i := 0; i < testLoginPolicy.UserThreshold; i++ {
+		if err := tracker.RecordFailure(user); err != nil {
+			t.Fatal(err)
+		}
+		if err := tracker.RecordFailure(ip); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if !throttled(t, tracker.Check(user)).Locked {
+		t.Fatal("user is not locked out")
+	}
+
+	// An admin unlock resets the username key, which the key normalizes
+	if err := tracker.Reset(domain.UsernameLoginKey(" Alice ")); err != nil {
+		t.Fatal(err)
+	}
+	if err := tracker.Check(user); err != nil {
+		t.Errorf("Check after Reset: %v", err)
+	}
+	if err := tracker.Check(ip); err == nil {
+		t.Error("resetting the user also cleared the IP's failures")
+	}
+
+	// The next failure starts a new count instead of locking again
+	if err := tracker.RecordFailure(user); err != nil {
+		t.Fatal(err)
+	}
+	if throttled(t, tracker.Check(user)).Locked {
+		t.Error("locked again by the first failure after Reset")
+	}
+}
@@ -0,0 +1,86 @@
+package repository
+
+import (
+	"sync"
+	"time"
+
+	"notes-app/internal/domain"
+)
+
+// memoryRefreshTokenRepository keeps refresh tokens in process memory. It only
+// suits a single instance, e.g. tests or local development.
+type memoryRefreshTokenRepository struct {
+	mu     sync.Mutex
+	nextID uint
+	tokens map[string]*domain.RefreshToken
+}
+
+func NewMemoryRefreshTokenRepository() domain.RefreshTokenRepository {
+	return &memoryRefreshTokenRepository{tokens: make(map[string]*domain.RefreshToken)}
+}
+
+func (r *memoryRefreshTokenRepository) Create(token *domain.RefreshToken) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.nextID++
+	token.ID = r.nextID
+	token.CreatedAt = time.Now()
+	stored := *token
+	r.tokens[token.JTI] = &stored
+	return nil
+}
+
+func (r *memoryRefreshTokenRepository) GetByJTI(jti string) (*domain.RefreshToken, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	token, ok := r.tokens[jti]
+	if !ok {
+		return nil, domain.ErrRefreshTokenInvalid
+	}
+	found := *token
+	return &found, nil
+}
+
+func (r *memoryRefreshTokenRepository) Use(jti string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	token, ok := r.tokens[jti]
+	if !ok || token.UsedAt != nil || token.RevokedAt != nil {
+		return domain.ErrRefreshTokenReused
+	}
+	now := time.Now()
+	token.UsedAt = &now
+	return nil
+}
+
+func (r *memoryRefreshTokenRepository) RevokeFamily(familyID string) error {
+	r.revokeWhere(func(token *domain.RefreshToken) bool { return token.FamilyID == familyID })
+	return nil
+}
+
+func (r *memoryRefreshTokenRepository) RevokeAllByUserID(userID uint) error {
+	r.revokeWhere(func(token *domain.RefreshToken) bool { return token.UserID == userID })
+	return nil
+}
+
+func (r *memoryRefreshTokenRepository) RevokeOthers(userID uint, keepFamilyID string) error {
+	r.revokeWhere(func(token *domain.RefreshToken) bool {
+		return token.UserID == userID && token.FamilyID != keepFamilyID
+	})
+	return nil
+}
+
+func (r *memoryRefreshTokenRepository) revokeWhere(match func(*domain.RefreshToken) bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	for _, token := range r.tokens {
+		if token.RevokedAt == nil && match(token) {
+			token.RevokedAt = &now
+		}
+	}
+}
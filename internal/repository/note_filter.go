@@ -0,0 +1,128 @@
+package repository
+
+import (
+	"fmt"
+	"strings"
+
+	"notes-app/internal/domain"
+)
+
+var noteFilterColumns = map[string]string{
+	"title":        "note_title",
+	"content":      "content",
+	"status":       "status",
+	"notebook_id":  "notebook_id",
+	"version":      "version",
+	"created_at":   "created_at",
+	"updated_at":   "updated_at",
+	"completed_at": "completed_at",
+}
+
+const noteHasTag = "EXISTS (SELECT 1 FROM note_tags JOIN tags ON tags.id = note_tags.tag_id " +
+	"WHERE note_tags.note_id = notes.id AND tags.name %s ?)"
+
+// compileNoteFilter turns a parsed filter into a SQL condition on the notes
+// table. Values are always bound as parameters, never spliced into the SQL.
+func compileNoteFilter(expr domain.FilterExpr) (string, []interface{}, error) {
+	switch e := expr.(type) {
+	case domain.FilterAnd:
+		return compileBinary(e.Left, e.Right, "AND")
+	case domain.FilterOr:
+		return compileBinary(e.Left, e.Right, "OR")
+	case domain.FilterNot:
+		sql, args, err := compileNoteFilter(e.Expr)
+		if err != nil {
+			return "", nil, err
+		}
+		return "NOT (" + sql + ")", args, nil
+	case domain.FilterCondition:
+		return compileCondition(e)
+	default:
+		return "", nil, fmt.Errorf("unsupported filter node %T", expr)
+	}
+}
+
+func compileBinary(left, right domain.FilterExpr, op string) (string, []interface{}, error) {
+	leftSQL, leftArgs, err := compileNoteFilter(left)
+	if err != nil {
+		return "", nil, err
+	}
+	rightSQL, rightArgs, err := compileNoteFilter(right)
+	if err != nil {
+		return "", nil, err
+	}
+	return "(" + leftSQL + " " + op + " " + rightSQL + ")", append(leftArgs, rightArgs...), nil
+}
+
+func compileCondition(cond domain.FilterCondition) (string, []interface{}, error) {
+	if cond.Field == "tag" {
+		switch cond.Op {
+		case domain.FilterEq:
+			return fmt.Sprintf(noteHasTag, "="), []interface{}{cond.Value}, nil
+		case domain.FilterContains:
+			return fmt.Sprintf(noteHasTag, "ILIKE"), []interface{}{containsPattern(cond.Value)}, nil
+		case domain.FilterNotEq:
+			return "NOT " + fmt.Sprintf(noteHasTag, "="), []interface{}{cond.Value}, nil
+		}
+		return "", nil, fmt.Errorf("operator %q is not supported for field %q", cond.Op, cond.Field)
+	}
+
+	column, ok := noteFilterColumns[cond.Field]
+	if !ok {
+		return "", nil, fmt.Errorf("%w: %s", domain.ErrUnknownField, cond.Field)
+	}
+
+	if r, ok := cond.Value.(domain.FilterTimeRange); ok {
+		return compileTimeCondition(column, cond.Op, r)
+	}
+
+	switch cond.Op {
+	case domain.FilterEq:
+		return column + " = ?", []interface{}{cond.Value}, nil
+	case domain.FilterContains:
+		return column + " ILIKE ?", []interface{}{containsPattern(cond.Value)}, nil
+	case domain.FilterNotEq:
+		return column + " IS DISTINCT FROM ?", []interface{}{cond.Value}, nil
+	case domain.FilterGt, domain.FilterGte, domain.FilterLt, domain.FilterLte:
+		return column + " " + cond.Op + " ?", []interface{}{cond.Value}, nil
+	}
+	return "", nil, fmt.Errorf("unsupported operator %q", cond.Op)
+}
+
+// compileTimeCondition treats a bare date as the whole day, so updated_at>2026-01-01
+// means "from 2026-01-02 on" and updated_at:2026-01-01 means "during that day".
+func compileTimeCondition(column, op string, r domain.FilterTimeRange) (string, []interface{}, error) {
+	if r.Start.Equal(r.End) {
+		switch op {
+		case domain.FilterEq:
+			return column + " = ?", []interface{}{r.Start}, nil
+		case domain.FilterNotEq:
+			return column + " IS DISTINCT FROM ?", []interface{}{r.Start}, nil
+		case domain.FilterGt, domain.FilterGte, domain.FilterLt, domain.FilterLte:
+			return column + " " + op + " ?", []interface{}{r.Start}, nil
+		}
+		return "", nil, fmt.Errorf("unsupported operator %q", op)
+	}
+
+	switch op {
+	case domain.FilterEq:
+		return "(" + column + " >= ? AND " + column + " < ?)", []interface{}{r.Start, r.End}, nil
+	case domain.FilterNotEq:
+		return "NOT (" + column + " >= ? AND " + column + " < ?)", []interface{}{r.Start, r.End}, nil
+	case domain.FilterGt:
+		return column + " >= ?", []interface{}{r.End}, nil
+	case domain.FilterGte:
+		return column + " >= ?", []interface{}{r.Start}, nil
+	case domain.FilterLt:
+		return column + " < ?", []interface{}{r.Start}, nil
+	case domain.FilterLte:
+		return column + " < ?", []interface{}{r.End}, nil
+	}
+	return "", nil, fmt.Errorf("unsupported operator %q", op)
+}
+
+// containsPattern escapes LIKE wildcards so the value matches literally anywhere in the column.
+func containsPattern(value interface{}) string {
+	escaped := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(fmt.Sprint(value))
+	return "%" + escaped + "%"
+}
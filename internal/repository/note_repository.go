@@ -1,10 +1,15 @@
 package repository
 
 import (
+	"database/sql"
 	"errors"
+	"fmt"
 	"notes-app/internal/domain"
+	"strings"
+	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type noteRepository struct {
@@ -16,49 +21,464 @@ func NewNoteRepository(db *gorm.DB) domain.NoteRepository {
 }
 
 func (r *noteRepository) Create(note *domain.Note) error {
-	return r.db.Create(note).Error
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if note.NotebookID != nil {
+			if _, err := findNotebook(tx, *note.NotebookID, note.UserID); err != nil {
+				return err
+			}
+		}
+		if err := tx.Omit(clause.Associations).Create(note).Error; err != nil {
+			return err
+		}
+		return createRevision(tx, note.ID)
+	})
 }
 
 func (r *noteRepository) GetByID(id, userID uint) (*domain.Note, error) {
 	var note domain.Note
-	err := r.db.Where("id = ? AND user_id = ?", id, userID).First(&note).Error
+	err := r.db.Preload("Tags").Where("id = ? AND user_id = ?", id, userID).First(&note).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errors.New("note not found or unauthorized")
+			return nil, domain.ErrNoteNotFound
 		}
 		return nil, err
 	}
 	return &note, nil
 }
 
-func (r *noteRepository) GetAllByUserID(userID uint) ([]domain.Note, error) {
+func (r *noteRepository) GetAllByUserID(userID uint, filter domain.NoteFilter, opts domain.NoteListOptions) (domain.Page[domain.Note], error) {
+	query := r.db.Where("user_id = ?", userID)
+
+	if len(filter.Tags) > 0 {
+		tagged := r.db.Table("note_tags").
+			Select("note_tags.note_id").
+			Joins("JOIN tags ON tags.id = note_tags.tag_id").
+			Where("tags.user_id = ? AND tags.name IN ?", userID, filter.Tags)
+		if filter.MatchAllTags {
+			// filter.Tags is de-duplicated by the usecase
+			tagged = tagged.Group("note_tags.note_id").
+				Having("COUNT(DISTINCT tags.name) = ?", len(filter.Tags))
+		}
+		query = query.Where("id IN (?)", tagged)
+	}
+	if filter.NotebookID != nil {
+		query = query.Where("notebook_id = ?", *filter.NotebookID)
+	}
+	if filter.Expr != nil {
+		condition, args, err := compileNoteFilter(filter.Expr)
+		if err != nil {
+			return domain.Page[domain.Note]{}, err
+		}
+		query = query.Where(condition, args...)
+	}
+
+	column, parse, cursorValue := noteSortColumn(opts.SortBy)
+	key := opts.SortBy + ":asc"
+	if opts.Descending {
+		key = opts.SortBy + ":desc"
+	}
+
+	if len(opts.Fields) > 0 {
+		query = query.Select(selectedNoteColumns(opts.Fields, column))
+	}
+	if len(opts.Fields) == 0 || containsString(opts.Fields, "tags") {
+		query = query.Preload("Tags")
+	}
+
+	query, err := keyset(query, opts.Page, key, column, opts.Descending, parse)
+	if err != nil {
+		return domain.Page[domain.Note]{}, err
+	}
+
 	var notes []domain.Note
-	err := r.db.Where("user_id = ?", userID).Find(&notes).Error
-	return notes, err
+	if err := query.Find(&notes).Error; err != nil {
+		return domain.Page[domain.Note]{}, err
+	}
+	return finishPage(notes, opts.Page, key, func(note domain.Note) (string, uint) {
+		return cursorValue(note), note.ID
+	}), nil
 }
 
+// Update changes the note's own fields and records the result as a new revision;
+// filing it into another notebook goes through Move. The version check and bump
+// happen in the same UPDATE statement so concurrent writers cannot both succeed.
+// On success note is reloaded with its new state.
 func (r *noteRepository) Update(note *domain.Note, userID uint) error {
-	result := r.db.Where("id = ? AND user_id = ?", note.ID, userID).Updates(note)
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := ensureBaseRevision(tx, note.ID, userID); err != nil {
+			return err
+		}
+
+		changes := map[string]interface{}{
+			"version": gorm.Expr("version + 1"),
+		}
+		if note.NoteTitle != "" {
+			changes["note_title"] = note.NoteTitle
+		}
+		if note.Content != "" {
+			changes["content"] = note.Content
+		}
+		if note.Status != "" {
+			changes["status"] = note.Status
+			changes["completed_at"] = note.CompletedAt
+		}
+
+		query := tx.Model(&domain.Note{}).Where("id = ? AND user_id = ?", note.ID, userID)
+		if note.Version > 0 {
+			query = query.Where("version = ?", note.Version)
+		}
+		result := query.Updates(changes)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return staleOrMissing(tx, note.ID, userID)
+		}
+
+		if err := createRevision(tx, note.ID); err != nil {
+			return err
+		}
+		return tx.Preload("Tags").First(note, note.ID).Error
+	})
+}
+
+// Delete moves the note to the trash; Purge removes it for good.
+func (r *noteRepository) Delete(id, userID uint, version int) error {
+	query := r.db.Where("id = ? AND user_id = ?", id, userID)
+	if version > 0 {
+		query = query.Where("version = ?", version)
+	}
+	result := query.Delete(&domain.Note{})
+	if result.Error != nil {
+		return result.Error
+	}
 	if result.RowsAffected == 0 {
-		return errors.New("note not found or unauthorized")
+		return staleOrMissing(r.db, id, userID)
 	}
-	return result.Error
+	return nil
 }
 
-func (r *noteRepository) Delete(id, userID uint) error {
-	result := r.db.Where("id = ? AND user_id = ?", id, userID).Delete(&domain.Note{})
-	if result.RowsAffected == 0 {
-		return errors.New("note not found or unauthorized")
+// Query runs a full-text search against the generated search_vector column.
+// The query string uses web search syntax: "quoted phrases", -exclusions and OR.
+func (r *noteRepository) Query(query string, userID uint, opts domain.NoteListOptions) (domain.Page[domain.NoteSearchResult], error) {
+	const tsQuery = "websearch_to_tsquery('english', @query)"
+
+	matches := r.db.Model(&domain.Note{}).
+		Select("notes.*, "+
+			"ts_rank(search_vector, "+tsQuery+")::float8 AS rank, "+
+			"ts_headline('english', content, "+tsQuery+", 'StartSel=<mark>, StopSel=</mark>, MaxFragments=2') AS snippet",
+			sql.Named("query", query)).
+		Where("user_id = @user_id AND search_vector @@ "+tsQuery,
+			sql.Named("user_id", userID), sql.Named("query", query))
+
+	column, parse, noteCursorValue := noteSortColumn(opts.SortBy)
+	cursorValue := func(result domain.NoteSearchResult) string { return noteCursorValue(result.Note) }
+	if opts.SortBy == domain.NoteSortRank {
+		column, parse = "rank", parseFloatCursor
+		cursorValue = func(result domain.NoteSearchResult) string { return formatFloatCursor(result.Rank) }
+	}
+	key := opts.SortBy + ":asc"
+	if opts.Descending {
+		key = opts.SortBy + ":desc"
+	}
+
+	// Paging on the computed rank needs it as a plain column, hence the subquery
+	search, err := keyset(r.db.Table("(?) AS matches", matches), opts.Page, key, column, opts.Descending, parse)
+	if err != nil {
+		return domain.Page[domain.NoteSearchResult]{}, err
+	}
+
+	var results []domain.NoteSearchResult
+	if err := search.Scan(&results).Error; err != nil {
+		return domain.Page[domain.NoteSearchResult]{}, err
+	}
+	if err := r.preloadSearchTags(results); err != nil {
+		return domain.Page[domain.NoteSearchResult]{}, err
+	}
+
+	return finishPage(results, opts.Page, key, func(result domain.NoteSearchResult) (string, uint) {
+		return cursorValue(result), result.ID
+	}), nil
+}
+
+// CountQueries counts the matches of several full-text queries in a single
+// scan of the user's notes, returning one count per query in order.
+func (r *noteRepository) CountQueries(queries []string, userID uint) ([]int64, error) {
+	if len(queries) == 0 {
+		return []int64{}, nil
+	}
+
+	columns := make([]string, len(queries))
+	args := make([]interface{}, len(queries))
+	for i, query := range queries {
+		columns[i] = fmt.Sprintf("COUNT(*) FILTER (WHERE search_vector @@ websearch_to_tsquery('english', ?)) AS c%d", i)
+		args[i] = query
+	}
+
+	rows, err := r.db.Model(&domain.Note{}).
+		Select(strings.Join(columns, ", "), args...).
+		Where("user_id = ?", userID).
+		Rows()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := make([]int64, len(queries))
+	targets := make([]interface{}, len(queries))
+	for i := range counts {
+		targets[i] = &counts[i]
+	}
+	if rows.Next() {
+		if err := rows.Scan(targets...); err != nil {
+			return nil, err
+		}
+	}
+	return counts, rows.Err()
+}
+
+// preloadSearchTags fills in Tags for search results, which are scanned rather than found.
+func (r *noteRepository) preloadSearchTags(results []domain.NoteSearchResult) error {
+	if len(results) == 0 {
+		return nil
+	}
+
+	ids := make([]uint, len(results))
+	for i := range results {
+		ids[i] = results[i].ID
+	}
+
+	var notes []domain.Note
+	if err := r.db.Preload("Tags").Select("id").Where("id IN ?", ids).Find(&notes).Error; err != nil {
+		return err
 	}
-	return result.Error
+
+	tags := make(map[uint][]domain.Tag, len(notes))
+	for _, note := range notes {
+		tags[note.ID] = note.Tags
+	}
+	for i := range results {
+		results[i].Tags = tags[results[i].ID]
+	}
+	return nil
 }
 
-func (r *noteRepository) Query(query string, userID uint) ([]domain.Note, error) {
+func (r *noteRepository) AttachTags(noteID, userID uint, tagIDs []uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		note := domain.Note{ID: noteID}
+		if err := tx.Where("id = ? AND user_id = ?", noteID, userID).First(&note).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return domain.ErrNoteNotFound
+			}
+			return err
+		}
+
+		var tags []domain.Tag
+		if err := tx.Where("id IN ? AND user_id = ?", tagIDs, userID).Find(&tags).Error; err != nil {
+			return err
+		}
+		if len(tags) != len(uniqueIDs(tagIDs)) {
+			return domain.ErrTagNotFound
+		}
+
+		if err := tx.Model(&note).Association("Tags").Append(tags); err != nil {
+			return err
+		}
+		return bumpVersions(tx.Model(&domain.Note{}).Where("id = ?", noteID))
+	})
+}
+
+// bumpVersions increments the version of the notes matched by query. Every write
+// that changes what GET /notes/:id returns must bump it, since the ETag and the
+// If-Match check are derived from the version alone.
+func bumpVersions(query *gorm.DB) error {
+	return query.UpdateColumn("version", gorm.Expr("version + 1")).Error
+}
+
+func (r *noteRepository) DetachTag(noteID, userID, tagID uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var note domain.Note
+		if err := tx.Where("id = ? AND user_id = ?", noteID, userID).First(&note).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return domain.ErrNoteNotFound
+			}
+			return err
+		}
+
+		result := tx.Exec("DELETE FROM note_tags WHERE note_id = ? AND tag_id = ?", noteID, tagID)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return domain.ErrTagNotFound
+		}
+		return bumpVersions(tx.Model(&domain.Note{}).Where("id = ?", noteID))
+	})
+}
+
+// Move files the note into a notebook, or takes it out of any notebook when notebookID is nil.
+func (r *noteRepository) Move(noteID, userID uint, notebookID *uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if notebookID != nil {
+			if _, err := findNotebook(tx, *notebookID, userID); err != nil {
+				return err
+			}
+		}
+
+		result := tx.Model(&domain.Note{}).
+			Where("id = ? AND user_id = ?", noteID, userID).
+			Updates(map[string]interface{}{
+				"notebook_id": notebookID,
+				"version":     gorm.Expr("version + 1"),
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return domain.ErrNoteNotFound
+		}
+		return nil
+	})
+}
+
+func (r *noteRepository) GetTrashByUserID(userID uint, page domain.PageRequest) (domain.Page[domain.Note], error) {
+	query := r.db.Unscoped().Preload("Tags").
+		Where("user_id = ? AND deleted_at IS NOT NULL", userID)
+
+	query, err := keyset(query, page, "deleted_at:desc", "deleted_at", true, parseTimeCursor)
+	if err != nil {
+		return domain.Page[domain.Note]{}, err
+	}
+
 	var notes []domain.Note
-	err := r.db.Where("user_id = ? AND (note_title ILIKE ? OR content ILIKE ?)",
-		userID,
-		"%"+query+"%",
-		"%"+query+"%").
-		Find(&notes).Error
-	return notes, err
+	if err := query.Find(&notes).Error; err != nil {
+		return domain.Page[domain.Note]{}, err
+	}
+	return finishPage(notes, page, "deleted_at:desc", func(note domain.Note) (string, uint) {
+		return formatTimeCursor(note.DeletedAt.Time), note.ID
+	}), nil
+}
+
+func (r *noteRepository) Restore(id, userID uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var note domain.Note
+		err := tx.Unscoped().Where("id = ? AND user_id = ? AND deleted_at IS NOT NULL", id, userID).First(&note).Error
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return domain.ErrNoteNotFound
+			}
+			return err
+		}
+
+		updates := map[string]interface{}{"deleted_at": nil, "version": gorm.Expr("version + 1")}
+		// The notebook may have been deleted while the note was in the trash
+		if note.NotebookID != nil {
+			if _, err := findNotebook(tx, *note.NotebookID, userID); errors.Is(err, domain.ErrNotebookNotFound) {
+				updates["notebook_id"] = nil
+			} else if err != nil {
+				return err
+			}
+		}
+
+		return tx.Unscoped().Model(&note).Updates(updates).Error
+	})
+}
+
+func (r *noteRepository) Purge(id, userID uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		trashed := tx.Unscoped().Model(&domain.Note{}).Select("id").
+			Where("id = ? AND user_id = ? AND deleted_at IS NOT NULL", id, userID)
+		purged, err := purgeNotes(tx, trashed)
+		if err != nil {
+			return err
+		}
+		if purged == 0 {
+			return domain.ErrNoteNotFound
+		}
+		return nil
+	})
+}
+
+func (r *noteRepository) PurgeDeletedBefore(cutoff time.Time) (int64, error) {
+	var purged int64
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		expired := tx.Unscoped().Model(&domain.Note{}).Select("id").
+			Where("deleted_at IS NOT NULL AND deleted_at < ?", cutoff)
+		var err error
+		purged, err = purgeNotes(tx, expired)
+		return err
+	})
+	return purged, err
+}
+
+// purgeNotes permanently deletes the notes selected by the ids subquery along with their tag links and revisions.
+func purgeNotes(tx *gorm.DB, ids *gorm.DB) (int64, error) {
+	if err := tx.Exec("DELETE FROM note_tags WHERE note_id IN (?)", ids).Error; err != nil {
+		return 0, err
+	}
+	if err := tx.Where("note_id IN (?)", ids).Delete(&domain.NoteRevision{}).Error; err != nil {
+		return 0, err
+	}
+	result := tx.Unscoped().Where("id IN (?)", ids).Delete(&domain.Note{})
+	return result.RowsAffected, result.Error
+}
+
+// staleOrMissing explains why a versioned write matched no rows.
+func staleOrMissing(db *gorm.DB, id, userID uint) error {
+	var count int64
+	if err := db.Model(&domain.Note{}).Where("id = ? AND user_id = ?", id, userID).Count(&count).Error; err != nil {
+		return err
+	}
+	if count == 0 {
+		return domain.ErrNoteNotFound
+	}
+	return domain.ErrVersionMismatch
+}
+
+// noteSortColumn maps a NoteListOptions.SortBy value to its column, cursor parser and cursor formatter.
+func noteSortColumn(sortBy string) (string, func(string) (interface{}, error), func(domain.Note) string) {
+	switch sortBy {
+	case domain.NoteSortUpdatedAt:
+		return "updated_at", parseTimeCursor, func(note domain.Note) string { return formatTimeCursor(note.UpdatedAt) }
+	case domain.NoteSortTitle:
+		return "note_title", parseStringCursor, func(note domain.Note) string { return note.NoteTitle }
+	default:
+		return "created_at", parseTimeCursor, func(note domain.Note) string { return formatTimeCursor(note.CreatedAt) }
+	}
+}
+
+// selectedNoteColumns returns the columns needed to render the requested fields
+// plus the ones keyset pagination depends on.
+func selectedNoteColumns(fields []string, sortColumn string) []string {
+	columns := []string{"id"}
+	if sortColumn != "id" {
+		columns = append(columns, sortColumn)
+	}
+	for _, field := range fields {
+		if field != "tags" && !containsString(columns, field) {
+			columns = append(columns, field)
+		}
+	}
+	return columns
+}
+
+func containsString(values []string, target string) bool {
+	for _, v := range values {
+		if v == target {
+			return true
+		}
+	}
+	return false
+}
+
+func uniqueIDs(ids []uint) []uint {
+	seen := make(map[uint]bool, len(ids))
+	var unique []uint
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+	return unique
 }
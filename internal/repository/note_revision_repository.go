@@ -0,0 +1,156 @@
+package repository
+
+import (
+	"errors"
+	"notes-app/internal/domain"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+func (r *noteRepository) GetRevisions(noteID, userID uint, page domain.PageRequest) (domain.Page[domain.NoteRevision], error) {
+	query := r.db.Where("note_id = ? AND user_id = ?", noteID, userID)
+
+	query, err := keyset(query, page, "revision:desc", "revision", true, parseIntCursor)
+	if err != nil {
+		return domain.Page[domain.NoteRevision]{}, err
+	}
+
+	var revisions []domain.NoteRevision
+	if err := query.Find(&revisions).Error; err != nil {
+		return domain.Page[domain.NoteRevision]{}, err
+	}
+	return finishPage(revisions, page, "revision:desc", func(rev domain.NoteRevision) (string, uint) {
+		return strconv.Itoa(rev.Revision), rev.ID
+	}), nil
+}
+
+func (r *noteRepository) GetRevision(noteID, userID uint, revision int) (*domain.NoteRevision, error) {
+	var rev domain.NoteRevision
+	err := r.db.Where("note_id = ? AND user_id = ? AND revision = ?", noteID, userID, revision).First(&rev).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrRevisionNotFound
+		}
+		return nil, err
+	}
+	return &rev, nil
+}
+
+// RestoreRevision copies a revision back onto the note. As an explicit rollback
+// it bypasses the status transition rules. The restore itself is recorded as a
+// new revision so it can be undone like any other edit.
+func (r *noteRepository) RestoreRevision(noteID, userID uint, revision int) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var rev domain.NoteRevision
+		err := tx.Where("note_id = ? AND user_id = ? AND revision = ?", noteID, userID, revision).First(&rev).Error
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return domain.ErrRevisionNotFound
+			}
+			return err
+		}
+
+		var completedAt interface{}
+		if rev.Status == domain.NoteStatusDone {
+			completedAt = gorm.Expr("COALESCE(completed_at, NOW())")
+		}
+
+		result := tx.Model(&domain.Note{}).
+			Where("id = ? AND user_id = ?", noteID, userID).
+			Updates(map[string]interface{}{
+				"note_title":   rev.NoteTitle,
+				"content":      rev.Content,
+				"status":       rev.Status,
+				"completed_at": completedAt,
+				"version":      gorm.Expr("version + 1"),
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return domain.ErrNoteNotFound
+		}
+		return createRevision(tx, noteID)
+	})
+}
+
+func (r *noteRepository) PruneRevisions(noteID uint, retention domain.RevisionRetention) error {
+	var latest int
+	err := r.db.Model(&domain.NoteRevision{}).
+		Where("note_id = ?", noteID).
+		Select("COALESCE(MAX(revision), 0)").
+		Scan(&latest).Error
+	if err != nil {
+		return err
+	}
+
+	if retention.KeepLast > 0 {
+		err := r.db.Where("note_id = ? AND revision <= ?", noteID, latest-retention.KeepLast).
+			Delete(&domain.NoteRevision{}).Error
+		if err != nil {
+			return err
+		}
+	}
+	if retention.KeepFor > 0 {
+		err := r.db.Where("note_id = ? AND revision < ? AND created_at < ?", noteID, latest, time.Now().Add(-retention.KeepFor)).
+			Delete(&domain.NoteRevision{}).Error
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// createRevision snapshots the note's current state as the next revision number.
+// It must run inside the transaction that modified the note so the row lock
+// taken by that write serializes revision numbering.
+func createRevision(tx *gorm.DB, noteID uint) error {
+	var note domain.Note
+	if err := tx.First(&note, noteID).Error; err != nil {
+		return err
+	}
+
+	var latest int
+	err := tx.Model(&domain.NoteRevision{}).
+		Where("note_id = ?", noteID).
+		Select("COALESCE(MAX(revision), 0)").
+		Scan(&latest).Error
+	if err != nil {
+		return err
+	}
+
+	return tx.Create(&domain.NoteRevision{
+		NoteID:    note.ID,
+		UserID:    note.UserID,
+		Revision:  latest + 1,
+		NoteTitle: note.NoteTitle,
+		Content:   note.Content,
+		Status:    note.Status,
+	}).Error
+}
+
+// ensureBaseRevision snapshots notes written before revision history existed,
+// so their original content is not lost on the first update. The note row is
+// locked first, so two concurrent first edits cannot both find no revision and
+// both insert revision 1.
+func ensureBaseRevision(tx *gorm.DB, noteID, userID uint) error {
+	var note domain.Note
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Select("id").Where("id = ? AND user_id = ?", noteID, userID).Take(&note).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return domain.ErrNoteNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	var count int64
+	err = tx.Model(&domain.NoteRevision{}).Where("note_id = ? AND user_id = ?", noteID, userID).Count(&count).Error
+	if err != nil || count > 0 {
+		return err
+	}
+	return createRevision(tx, noteID)
+}
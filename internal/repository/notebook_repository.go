@@ -0,0 +1,185 @@
+package repository
+
+import (
+	"errors"
+	"notes-app/internal/domain"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type notebookRepository struct {
+	db *gorm.DB
+}
+
+func NewNotebookRepository(db *gorm.DB) domain.NotebookRepository {
+	return &notebookRepository{db}
+}
+
+func (r *notebookRepository) Create(notebook *domain.Notebook) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if notebook.ParentID != nil {
+			if _, err := findNotebook(tx, *notebook.ParentID, notebook.UserID); err != nil {
+				return err
+			}
+		}
+		return tx.Create(notebook).Error
+	})
+}
+
+func (r *notebookRepository) GetByID(id, userID uint) (*domain.Notebook, error) {
+	return findNotebook(r.db, id, userID)
+}
+
+func (r *notebookRepository) GetAllByUserID(userID uint, page domain.PageRequest) (domain.Page[domain.Notebook], error) {
+	query, err := keyset(r.db.Where("user_id = ?", userID), page, "name:asc", "name", false, parseStringCursor)
+	if err != nil {
+		return domain.Page[domain.Notebook]{}, err
+	}
+
+	var notebooks []domain.Notebook
+	if err := query.Find(&notebooks).Error; err != nil {
+		return domain.Page[domain.Notebook]{}, err
+	}
+	return finishPage(notebooks, page, "name:asc", func(nb domain.Notebook) (string, uint) {
+		return nb.Name, nb.ID
+	}), nil
+}
+
+func (r *notebookRepository) GetSubtree(id, userID uint) ([]domain.Notebook, []domain.Note, error) {
+	var notebooks []domain.Notebook
+	var notes []domain.Note
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		ids, err := subtreeIDs(tx, id, userID)
+		if err != nil {
+			return err
+		}
+		if err := tx.Where("id IN ?", ids).Order("name").Find(&notebooks).Error; err != nil {
+			return err
+		}
+		return tx.Preload("Tags").Where("user_id = ? AND notebook_id IN ?", userID, ids).Find(&notes).Error
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return notebooks, notes, nil
+}
+
+func (r *notebookRepository) Rename(id, userID uint, name string) error {
+	result := r.db.Model(&domain.Notebook{}).
+		Where("id = ? AND user_id = ?", id, userID).
+		Update("name", name)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrNotebookNotFound
+	}
+	return nil
+}
+
+// Move re-parents a notebook together with its whole subtree. A nil parentID
+// moves it to the top level.
+func (r *notebookRepository) Move(id, userID uint, parentID *uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		// Lock the user's notebooks so concurrent moves cannot build a cycle
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Select("id").Where("user_id = ?", userID).Find(&[]domain.Notebook{}).Error; err != nil {
+			return err
+		}
+
+		ids, err := subtreeIDs(tx, id, userID)
+		if err != nil {
+			return err
+		}
+
+		if parentID != nil {
+			if _, err := findNotebook(tx, *parentID, userID); err != nil {
+				return err
+			}
+			for _, descendant := range ids {
+				if descendant == *parentID {
+					return domain.ErrNotebookCycle
+				}
+			}
+		}
+
+		return tx.Model(&domain.Notebook{}).
+			Where("id = ? AND user_id = ?", id, userID).
+			Update("parent_id", parentID).Error
+	})
+}
+
+func (r *notebookRepository) Delete(id, userID uint, mode string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		notebook, err := findNotebook(tx, id, userID)
+		if err != nil {
+			return err
+		}
+
+		switch mode {
+		case domain.NotebookDeleteCascade:
+			ids, err := subtreeIDs(tx, id, userID)
+			if err != nil {
+				return err
+			}
+			// Notes go to the trash so they can still be restored
+			if err := tx.Where("user_id = ? AND notebook_id IN ?", userID, ids).Delete(&domain.Note{}).Error; err != nil {
+				return err
+			}
+			return tx.Where("id IN ?", ids).Delete(&domain.Notebook{}).Error
+
+		case domain.NotebookDeleteRehome:
+			if err := tx.Model(&domain.Notebook{}).
+				Where("parent_id = ? AND user_id = ?", id, userID).
+				Update("parent_id", notebook.ParentID).Error; err != nil {
+				return err
+			}
+			if err := tx.Model(&domain.Note{}).
+				Where("notebook_id = ? AND user_id = ?", id, userID).
+				Updates(map[string]interface{}{
+					"notebook_id": notebook.ParentID,
+					"version":     gorm.Expr("version + 1"),
+				}).Error; err != nil {
+				return err
+			}
+			return tx.Delete(notebook).Error
+
+		default:
+			return domain.ErrInvalidDeleteMode
+		}
+	})
+}
+
+func findNotebook(db *gorm.DB, id, userID uint) (*domain.Notebook, error) {
+	var notebook domain.Notebook
+	err := db.Where("id = ? AND user_id = ?", id, userID).First(&notebook).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrNotebookNotFound
+		}
+		return nil, err
+	}
+	return &notebook, nil
+}
+
+// subtreeIDs returns the notebook itself followed by all of its descendants.
+func subtreeIDs(db *gorm.DB, id, userID uint) ([]uint, error) {
+	var ids []uint
+	err := db.Raw(`
+		WITH RECURSIVE subtree AS (
+			SELECT id FROM notebooks WHERE id = ? AND user_id = ?
+			UNION ALL
+			SELECT n.id FROM notebooks n JOIN subtree s ON n.parent_id = s.id
+		)
+		SELECT id FROM subtree
+	`, id, userID).Scan(&ids).Error
+	if err != nil {
+		return nil, err
+	}
+	if len(ids) == 0 {
+		return nil, domain.ErrNotebookNotFound
+	}
+	return ids, nil
+}
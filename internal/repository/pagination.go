@@ -0,0 +1,81 @@
+package repository
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"notes-app/internal/domain"
+	"notes-app/pkg/pagination"
+
+	"gorm.io/gorm"
+)
+
+// keyset orders query by (expr, id) and restricts it to rows after the page
+// cursor. It fetches one row more than the limit so finishPage can tell
+// whether another page follows. key must be unique per ordering.
+func keyset(query *gorm.DB, page domain.PageRequest, key, expr string, desc bool, parse func(string) (interface{}, error)) (*gorm.DB, error) {
+	cursor, err := pagination.Decode(page.Cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	direction, comparison := "ASC", ">"
+	if desc {
+		direction, comparison = "DESC", "<"
+	}
+
+	if cursor != nil {
+		if cursor.Key != key {
+			return nil, domain.ErrInvalidCursor
+		}
+		value, err := parse(cursor.Value)
+		if err != nil {
+			return nil, domain.ErrInvalidCursor
+		}
+		query = query.Where(fmt.Sprintf("(%s, id) %s (?, ?)", expr, comparison), value, cursor.ID)
+	}
+
+	return query.
+		Order(fmt.Sprintf("%s %s, id %s", expr, direction, direction)).
+		Limit(page.Limit + 1), nil
+}
+
+// finishPage trims the extra row fetched by keyset and builds the cursor for
+// the next page from the last row kept.
+func finishPage[T any](items []T, page domain.PageRequest, key string, cursorOf func(T) (string, uint)) domain.Page[T] {
+	result := domain.Page[T]{Data: items}
+	if result.Data == nil {
+		result.Data = []T{}
+	}
+	if len(items) > page.Limit {
+		result.Data = items[:page.Limit]
+		value, id := cursorOf(result.Data[page.Limit-1])
+		result.NextCursor = pagination.Encode(pagination.Cursor{Key: key, Value: value, ID: id})
+	}
+	return result
+}
+
+func parseTimeCursor(value string) (interface{}, error) {
+	return time.Parse(time.RFC3339Nano, value)
+}
+
+func parseStringCursor(value string) (interface{}, error) {
+	return value, nil
+}
+
+func parseIntCursor(value string) (interface{}, error) {
+	return strconv.Atoi(value)
+}
+
+func parseFloatCursor(value string) (interface{}, error) {
+	return strconv.ParseFloat(value, 64)
+}
+
+func formatTimeCursor(t time.Time) string {
+	return t.Format(time.RFC3339Nano)
+}
+
+func formatFloatCursor(f float64) string {
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
@@ -0,0 +1,47 @@
+package repository
+
+import (
+	"time"
+
+	"notes-app/internal/domain"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type passwordResetRepository struct {
+	db *gorm.DB
+}
+
+func NewPasswordResetRepository(db *gorm.DB) domain.PasswordResetRepository {
+	return &passwordResetRepository{db}
+}
+
+func (r *passwordResetRepository) Create(token *domain.PasswordResetToken) error {
+	return r.db.Create(token).Error
+}
+
+// Use is a compare-and-swap on used_at, so of two concurrent resets with the
+// same token only one succeeds.
+func (r *passwordResetRepository) Use(tokenHash string) (*domain.PasswordResetToken, error) {
+	now := time.Now()
+	var token domain.PasswordResetToken
+	result := r.db.Model(&token).Clauses(clause.Returning{}).
+		Where("token_hash = ? AND used_at IS NULL AND expires_at > ?", tokenHash, now).
+		Update("used_at", now)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, domain.ErrPasswordResetInvalid
+	}
+	return &token, nil
+}
+
+// InvalidateAllByUserID spends the user's outstanding tokens, so only the most
+// recently mailed one works.
+func (r *passwordResetRepository) InvalidateAllByUserID(userID uint) error {
+	return r.db.Model(&domain.PasswordResetToken{}).
+		Where("user_id = ? AND used_at IS NULL", userID).
+		Update("used_at", time.Now()).Error
+}
@@ -0,0 +1,93 @@
+package repository
+
+import (
+	"errors"
+	"notes-app/internal/domain"
+
+	"gorm.io/gorm"
+)
+
+type savedSearchRepository struct {
+	db *gorm.DB
+}
+
+func NewSavedSearchRepository(db *gorm.DB) domain.SavedSearchRepository {
+	return &savedSearchRepository{db}
+}
+
+func (r *savedSearchRepository) Create(search *domain.SavedSearch) error {
+	return r.db.Create(search).Error
+}
+
+func (r *savedSearchRepository) GetByID(id, userID uint) (*domain.SavedSearch, error) {
+	var search domain.SavedSearch
+	err := r.db.Where("id = ? AND user_id = ?", id, userID).First(&search).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrSavedSearchNotFound
+		}
+		return nil, err
+	}
+	return &search, nil
+}
+
+func (r *savedSearchRepository) GetByName(name string, userID uint) (*domain.SavedSearch, error) {
+	var search domain.SavedSearch
+	err := r.db.Where("name = ? AND user_id = ?", name, userID).First(&search).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrSavedSearchNotFound
+		}
+		return nil, err
+	}
+	return &search, nil
+}
+
+func (r *savedSearchRepository) GetAllByUserID(userID uint, page domain.PageRequest) (domain.Page[domain.SavedSearch], error) {
+	query, err := keyset(r.db.Where("user_id = ?", userID), page, "name:asc", "name", false, parseStringCursor)
+	if err != nil {
+		return domain.Page[domain.SavedSearch]{}, err
+	}
+
+	var searches []domain.SavedSearch
+	if err := query.Find(&searches).Error; err != nil {
+		return domain.Page[domain.SavedSearch]{}, err
+	}
+	return finishPage(searches, page, "name:asc", func(search domain.SavedSearch) (string, uint) {
+		return search.Name, search.ID
+	}), nil
+}
+
+func (r *savedSearchRepository) ListByUserID(userID uint) ([]domain.SavedSearch, error) {
+	var searches []domain.SavedSearch
+	err := r.db.Where("user_id = ?", userID).Order("name").Find(&searches).Error
+	return searches, err
+}
+
+func (r *savedSearchRepository) Update(search *domain.SavedSearch, userID uint) error {
+	result := r.db.Model(&domain.SavedSearch{}).
+		Where("id = ? AND user_id = ?", search.ID, userID).
+		Updates(map[string]interface{}{
+			"name":  search.Name,
+			"query": search.Query,
+			"sort":  search.Sort,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrSavedSearchNotFound
+	}
+	return nil
+}
+
+func (r *savedSearchRepository) Delete(id, userID uint) error {
+	result := r.db.Where("id = ? AND user_id = ?", id, userID).Delete(&domain.SavedSearch{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrSavedSearchNotFound
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"notes-app/internal/domain"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type sessionRepository struct {
+	db *gorm.DB
+}
+
+func NewSessionRepository(db *gorm.DB) domain.SessionRepository {
+	return &sessionRepository{db}
+}
+
+func (r *sessionRepository) Create(session *domain.Session) error {
+	return r.db.Create(session).Error
+}
+
+func (r *sessionRepository) GetByFamilyID(familyID string) (*domain.Session, error) {
+	var session domain.Session
+	err := r.db.Where("family_id = ?", familyID).First(&session).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrSessionNotFound
+		}
+		return nil, err
+	}
+	return &session, nil
+}
+
+func (r *sessionRepository) GetActiveByUserID(userID uint, page domain.PageRequest) (domain.Page[domain.Session], error) {
+	base := r.db.Where("user_id = ? AND revoked_at IS NULL", userID)
+	query, err := keyset(base, page, "last_used_at:desc", "last_used_at", true, parseTimeCursor)
+	if err != nil {
+		return domain.Page[domain.Session]{}, err
+	}
+
+	var sessions []domain.Session
+	if err := query.Find(&sessions).Error; err != nil {
+		return domain.Page[domain.Session]{}, err
+	}
+	return finishPage(sessions, page, "last_used_at:desc", func(session domain.Session) (string, uint) {
+		return formatTimeCursor(session.LastUsedAt), session.ID
+	}), nil
+}
+
+func (r *sessionRepository) Touch(id uint, client domain.ClientInfo) error {
+	return r.db.Model(&domain.Session{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"last_used_at": time.Now(),
+		"user_agent":   client.UserAgent,
+		"ip_address":   client.IPAddress,
+	}).Error
+}
+
+// Revoke returns the revoked session so the caller can revoke its refresh
+// tokens as well.
+func (r *sessionRepository) Revoke(id, userID uint) (*domain.Session, error) {
+	var session domain.Session
+	result := r.db.Model(&session).Clauses(clause.Returning{}).
+		Where("id = ? AND user_id = ? AND revoked_at IS NULL", id, userID).
+		Update("revoked_at", time.Now())
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, domain.ErrSessionNotFound
+	}
+	return &session, nil
+}
+
+func (r *sessionRepository) RevokeAllByUserID(userID uint) error {
+	return r.db.Model(&domain.Session{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", time.Now()).Error
+}
+
+// RevokeOthers revokes every session of the user except the one of keepFamilyID.
+func (r *sessionRepository) RevokeOthers(userID uint, keepFamilyID string) error {
+	return r.db.Model(&domain.Session{}).
+		Where("user_id = ? AND family_id <> ? AND revoked_at IS NULL", userID, keepFamilyID).
+		Update("revoked_at", time.Now()).Error
+}
@@ -0,0 +1,127 @@
+package repository
+
+import (
+	"errors"
+	"notes-app/internal/domain"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+)
+
+// tagNameIndex is the unique index on a user's tag names.
+const tagNameIndex = "idx_tags_user_name"
+
+type tagRepository struct {
+	db *gorm.DB
+}
+
+func NewTagRepository(db *gorm.DB) domain.TagRepository {
+	return &tagRepository{db}
+}
+
+// Create fails with ErrTagNameTaken when the user already has a tag of that
+// name, including one created concurrently after the usecase checked.
+func (r *tagRepository) Create(tag *domain.Tag) error {
+	err := r.db.Create(tag).Error
+	if isUniqueViolation(err, tagNameIndex) {
+		return domain.ErrTagNameTaken
+	}
+	return err
+}
+
+func (r *tagRepository) GetByID(id, userID uint) (*domain.Tag, error) {
+	var tag domain.Tag
+	err := r.db.Where("id = ? AND user_id = ?", id, userID).First(&tag).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrTagNotFound
+		}
+		return nil, err
+	}
+	return &tag, nil
+}
+
+func (r *tagRepository) GetByName(name string, userID uint) (*domain.Tag, error) {
+	var tag domain.Tag
+	err := r.db.Where("name = ? AND user_id = ?", name, userID).First(&tag).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrTagNotFound
+		}
+		return nil, err
+	}
+	return &tag, nil
+}
+
+func (r *tagRepository) GetAllByUserID(userID uint, page domain.PageRequest) (domain.Page[domain.Tag], error) {
+	query, err := keyset(r.db.Where("user_id = ?", userID), page, "name:asc", "name", false, parseStringCursor)
+	if err != nil {
+		return domain.Page[domain.Tag]{}, err
+	}
+
+	var tags []domain.Tag
+	if err := query.Find(&tags).Error; err != nil {
+		return domain.Page[domain.Tag]{}, err
+	}
+	return finishPage(tags, page, "name:asc", func(tag domain.Tag) (string, uint) {
+		return tag.Name, tag.ID
+	}), nil
+}
+
+// Update renames the tag in place. Notes reference tags by ID, so the rename
+// changes every tagged note; their versions are bumped so cached ETags go stale.
+func (r *tagRepository) Update(tag *domain.Tag, userID uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&domain.Tag{}).
+			Where("id = ? AND user_id = ?", tag.ID, userID).
+			Update("name", tag.Name)
+		if isUniqueViolation(result.Error, tagNameIndex) {
+			return domain.ErrTagNameTaken
+		}
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return domain.ErrTagNotFound
+		}
+		return bumpVersions(taggedNotes(tx, tag.ID))
+	})
+}
+
+// taggedNotes matches the notes the tag is attached to.
+func taggedNotes(tx *gorm.DB, tagID uint) *gorm.DB {
+	return tx.Unscoped().Model(&domain.Note{}).Where("id IN (SELECT note_id FROM note_tags WHERE tag_id = ?)", tagID)
+}
+
+func (r *tagRepository) Delete(id, userID uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var tag domain.Tag
+		if err := tx.Where("id = ? AND user_id = ?", id, userID).First(&tag).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return domain.ErrTagNotFound
+			}
+			return err
+		}
+		if err := bumpVersions(taggedNotes(tx, id)); err != nil {
+			return err
+		}
+		if err := tx.Exec("DELETE FROM note_tags WHERE tag_id IN (SELECT id FROM tags WHERE id = ? AND user_id = ?)", id, userID).Error; err != nil {
+			return err
+		}
+		result := tx.Where("id = ? AND user_id = ?", id, userID).Delete(&domain.Tag{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return domain.ErrTagNotFound
+		}
+		return nil
+	})
+}
+
+// isUniqueViolation reports whether err is Postgres rejecting a duplicate in
+// the named unique index.
+func isUniqueViolation(err error, index string) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505" && pgErr.ConstraintName == index
+}
@@ -0,0 +1,97 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"notes-app/internal/domain"
+
+	"gorm.io/gorm"
+)
+
+type twoFactorRepository struct {
+	db *gorm.DB
+}
+
+func NewTwoFactorRepository(db *gorm.DB) domain.TwoFactorRepository {
+	return &twoFactorRepository{db}
+}
+
+func (r *twoFactorRepository) Get(userID uint) (*domain.TwoFactor, error) {
+	var twoFactor domain.TwoFactor
+	err := r.db.Where("user_id = ?", userID).First(&twoFactor).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrTwoFactorNotSetUp
+		}
+		return nil, err
+	}
+	return &twoFactor, nil
+}
+
+// Save creates or replaces the user's (pending) secret.
+func (r *twoFactorRepository) Save(twoFactor *domain.TwoFactor) error {
+	return r.db.Save(twoFactor).Error
+}
+
+// Enable activates the pending secret, recording step as used, and replaces
+// any recovery codes in the same transaction.
+func (r *twoFactorRepository) Enable(userID uint, step int64, recoveryCodeHashes []string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&domain.TwoFactor{}).
+			Where("user_id = ? AND enabled_at IS NULL", userID).
+			Updates(map[string]interface{}{"enabled_at": time.Now(), "last_used_step": step})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return domain.ErrTwoFactorNotSetUp
+		}
+
+		if err := tx.Where("user_id = ?", userID).Delete(&domain.RecoveryCode{}).Error; err != nil {
+			return err
+		}
+		codes := make([]domain.RecoveryCode, len(recoveryCodeHashes))
+		for i, hash := range recoveryCodeHashes {
+			codes[i] = domain.RecoveryCode{UserID: userID, CodeHash: hash}
+		}
+		return tx.Create(&codes).Error
+	})
+}
+
+func (r *twoFactorRepository) Delete(userID uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&domain.RecoveryCode{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("user_id = ?", userID).Delete(&domain.TwoFactor{}).Error
+	})
+}
+
+// UseStep records step as used unless a code of the same or a later step was
+// already accepted, which makes each code single use even under concurrency.
+func (r *twoFactorRepository) UseStep(userID uint, step int64) error {
+	result := r.db.Model(&domain.TwoFactor{}).
+		Where("user_id = ? AND enabled_at IS NOT NULL AND last_used_step < ?", userID, step).
+		Update("last_used_step", step)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrTwoFactorCodeInvalid
+	}
+	return nil
+}
+
+func (r *twoFactorRepository) UseRecoveryCode(userID uint, codeHash string) error {
+	result := r.db.Model(&domain.RecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, codeHash).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrTwoFactorCodeInvalid
+	}
+	return nil
+}
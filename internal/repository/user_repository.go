@@ -1,6 +1,9 @@
 package repository
 
 import (
+	"errors"
+	"time"
+
 	"notes-app/internal/domain"
 
 	"gorm.io/gorm"
@@ -14,14 +17,48 @@ func NewUserRepository(db *gorm.DB) domain.UserRepository {
 	return &userRepository{db}
 }
 
+// firstUserLockKey identifies the advisory lock serializing registrations while
+// the users table is empty. It must differ from the migration lock key.
+const firstUserLockKey int64 = 0x6669727374 // "first"
+
+// Create makes user an admin when no account exists yet. Once there are users
+// the insert runs unlocked; only while the table looks empty do registrations
+// take an advisory lock and check again, so two simultaneous first
+// registrations cannot both become admin.
 func (r *userRepository) Create(user *domain.User) error {
-	return r.db.Create(user).Error
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		first, err := noUsers(tx)
+		if err != nil {
+			return err
+		}
+		if first {
+			if err := tx.Exec("SELECT pg_advisory_xact_lock(?)", firstUserLockKey).Error; err != nil {
+				return err
+			}
+			if first, err = noUsers(tx); err != nil {
+				return err
+			}
+		}
+		if first {
+			user.Role = domain.RoleAdmin
+		}
+		return tx.Create(user).Error
+	})
+}
+
+func noUsers(tx *gorm.DB) (bool, error) {
+	var exists bool
+	err := tx.Raw("SELECT EXISTS (SELECT 1 FROM users)").Scan(&exists).Error
+	return !exists, err
 }
 
 func (r *userRepository) GetByUsername(username string) (*domain.User, error) {
 	var user domain.User
 	err := r.db.Where("username = ?", username).First(&user).Error
 	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrUserNotFound
+		}
 		return nil, err
 	}
 	return &user, nil
@@ -31,7 +68,94 @@ func (r *userRepository) GetByID(id uint) (*domain.User, error) {
 	var user domain.User
 	err := r.db.First(&user, id).Error
 	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrUserNotFound
+		}
 		return nil, err
 	}
 	return &user, nil
 }
+
+// Search lists accounts whose username contains query, ordered by username,
+// with the number of notes (outside the trash) each one owns.
+func (r *userRepository) Search(query string, page domain.PageRequest) (domain.Page[domain.UserSummary], error) {
+	base := r.summaries()
+	if query != "" {
+		base = base.Where("users.username ILIKE ?", containsPattern(query))
+	}
+
+	paged, err := keyset(base, page, "username:asc", "users.username", false, parseStringCursor)
+	if err != nil {
+		return domain.Page[domain.UserSummary]{}, err
+	}
+
+	var users []domain.UserSummary
+	if err := paged.Scan(&users).Error; err != nil {
+		return domain.Page[domain.UserSummary]{}, err
+	}
+	return finishPage(users, page, "username:asc", func(user domain.UserSummary) (string, uint) {
+		return user.Username, user.ID
+	}), nil
+}
+
+func (r *userRepository) GetSummary(id uint) (*domain.UserSummary, error) {
+	var users []domain.UserSummary
+	if err := r.summaries().Where("users.id = ?", id).Scan(&users).Error; err != nil {
+		return nil, err
+	}
+	if len(users) == 0 {
+		return nil, domain.ErrUserNotFound
+	}
+	return &users[0], nil
+}
+
+func (r *userRepository) SetDisabledAt(id uint, disabledAt *time.Time) error {
+	result := r.db.Model(&domain.User{}).Where("id = ?", id).Update("disabled_at", disabledAt)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrUserNotFound
+	}
+	return nil
+}
+
+func (r *userRepository) UpdatePassword(id uint, passwordHash string) error {
+	result := r.db.Model(&domain.User{}).Where("id = ?", id).Update("password", passwordHash)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrUserNotFound
+	}
+	return nil
+}
+
+func (r *userRepository) SetVerifiedEmail(id uint, email string, verifiedAt time.Time) error {
+	result := r.db.Model(&domain.User{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"email":             email,
+		"email_verified_at": verifiedAt,
+	})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrUserNotFound
+	}
+	return nil
+}
+
+func (r *userRepository) PromoteToAdmin(usernames []string) (int64, error) {
+	if len(usernames) == 0 {
+		return 0, nil
+	}
+	result := r.db.Model(&domain.User{}).
+		Where("username IN ? AND role <> ?", usernames, domain.RoleAdmin).
+		Update("role", domain.RoleAdmin)
+	return result.RowsAffected, result.Error
+}
+
+func (r *userRepository) summaries() *gorm.DB {
+	return r.db.Model(&domain.User{}).Select(`users.id, users.username, users.role, users.disabled_at, users.created_at,
+		(SELECT COUNT(*) FROM notes WHERE notes.user_id = users.id AND notes.deleted_at IS NULL) AS note_count`)
+}
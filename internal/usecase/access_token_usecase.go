@@ -0,0 +1,114 @@
+package usecase
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"notes-app/internal/domain"
+	"notes-app/pkg/auth"
+	"notes-app/pkg/types"
+)
+
+// accessTokenType is the token_type reported in the claims of a personal access token.
+const accessTokenType = "personal_access_token"
+
+type accessTokenUsecase struct {
+	tokenRepo domain.PersonalAccessTokenRepository
+	userRepo  domain.UserRepository
+}
+
+func NewAccessTokenUsecase(repo domain.PersonalAccessTokenRepository, userRepo domain.UserRepository) domain.PersonalAccessTokenUsecase {
+	return &accessTokenUsecase{
+		tokenRepo: repo,
+		userRepo:  userRepo,
+	}
+}
+
+// Create generates the token and stores its hash; token.Token holds the only
+// copy of the plain token. A token can only be granted scopes the caller has, so
+// a limited token cannot mint a broader one, and never the account scope.
+func (u *accessTokenUsecase) Create(token *domain.PersonalAccessToken, user *domain.User, callerScopes []string) error {
+	token.Name = strings.TrimSpace(token.Name)
+	if token.Name == "" || len(token.Scopes) == 0 {
+		return domain.ErrAccessTokenRequest
+	}
+	for _, scope := range token.Scopes {
+		if !domain.ValidScope(scope) {
+			return domain.ErrInvalidScope
+		}
+		if scope == domain.ScopeAccount || !contains(callerScopes, scope) {
+			return fmt.Errorf("%w: %s", domain.ErrScopeNotGrantable, scope)
+		}
+	}
+	if token.ExpiresAt != nil && !token.ExpiresAt.After(time.Now()) {
+		return domain.ErrAccessTokenExpiry
+	}
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return err
+	}
+	plain := domain.AccessTokenPrefix + base64.RawURLEncoding.EncodeToString(secret)
+
+	token.ID = 0
+	token.UserID = user.ID
+	token.Prefix = plain[:len(domain.AccessTokenPrefix)+6]
+	token.TokenHash = auth.HashToken(plain)
+	token.LastUsedAt = nil
+	if err := u.tokenRepo.Create(token); err != nil {
+		return err
+	}
+
+	token.Token = plain
+	return nil
+}
+
+func (u *accessTokenUsecase) GetAll(user *domain.User, page domain.PageRequest) (domain.Page[domain.PersonalAccessToken], error) {
+	return u.tokenRepo.GetAllByUserID(user.ID, page.Normalized())
+}
+
+func (u *accessTokenUsecase) Revoke(id uint, user *domain.User) error {
+	return u.tokenRepo.Delete(id, user.ID)
+}
+
+func (u *accessTokenUsecase) Authenticate(token string) (*domain.User, *types.UserClaims, error) {
+	stored, err := u.tokenRepo.GetByHash(auth.HashToken(token))
+	if err != nil {
+		return nil, nil, err
+	}
+	now := time.Now()
+	if stored.ExpiresAt != nil && !stored.ExpiresAt.After(now) {
+		return nil, nil, domain.ErrAccessTokenInvalid
+	}
+
+	user, err := u.userRepo.GetByID(stored.UserID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if user.DisabledAt != nil {
+		return nil, nil, domain.ErrUserDisabled
+	}
+
+	// Last-used tracking is informational; it must not fail the request.
+	if err := u.tokenRepo.Touch(stored.ID, now); err != nil {
+		log.Printf("Failed to record use of access token %d: %v", stored.ID, err)
+	}
+
+	// A token never exceeds its owner's current scopes, e.g. after a demotion.
+	var scopes []string
+	for _, scope := range stored.Scopes {
+		if contains(userScopes(user), scope) {
+			scopes = append(scopes, scope)
+		}
+	}
+
+	return user, &types.UserClaims{
+		ID:     user.ID,
+		Type:   accessTokenType,
+		Scopes: scopes,
+	}, nil
+}
@@ -0,0 +1,92 @@
+package usecase
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"strings"
+	"time"
+
+	"notes-app/internal/domain"
+)
+
+type adminUsecase struct {
+	userRepo         domain.UserRepository
+	sessionRepo      domain.SessionRepository
+	refreshTokenRepo domain.RefreshTokenRepository
+	loginAttempts    domain.LoginAttemptTracker
+	passwordHasher   domain.PasswordHasher
+}
+
+func NewAdminUsecase(userRepo domain.UserRepository, sessionRepo domain.SessionRepository, refreshTokenRepo domain.RefreshTokenRepository,
+	loginAttempts domain.LoginAttemptTracker, passwordHasher domain.PasswordHasher) domain.AdminUsecase {
+	return &adminUsecase{
+		userRepo:         userRepo,
+		sessionRepo:      sessionRepo,
+		refreshTokenRepo: refreshTokenRepo,
+		loginAttempts:    loginAttempts,
+		passwordHasher:   passwordHasher,
+	}
+}
+
+func (u *adminUsecase) ListUsers(query string, page domain.PageRequest) (domain.Page[domain.UserSummary], error) {
+	return u.userRepo.Search(strings.TrimSpace(query), page.Normalized())
+}
+
+func (u *adminUsecase) GetUser(id uint) (*domain.UserSummary, error) {
+	return u.userRepo.GetSummary(id)
+}
+
+// SetDisabled disables or re-enables an account. A disabled user's tokens are
+// rejected on their next request; re-enabling restores them.
+func (u *adminUsecase) SetDisabled(id uint, disabled bool, admin *domain.User) (*domain.UserSummary, error) {
+	var disabledAt *time.Time
+	if disabled {
+		if id == admin.ID {
+			return nil, domain.ErrCannotDisableSelf
+		}
+		now := time.Now()
+		disabledAt = &now
+	}
+
+	if err := u.userRepo.SetDisabledAt(id, disabledAt); err != nil {
+		return nil, err
+	}
+	return u.userRepo.GetSummary(id)
+}
+
+// ResetPassword replaces the user's password with a random temporary one,
+// returned so the administrator can hand it over, and ends all of the user's
+// sessions.
+func (u *adminUsecase) ResetPassword(id uint) (string, error) {
+	secret := make([]byte, 12)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+	temporary := base64.RawURLEncoding.EncodeToString(secret)
+
+	hashedPassword, err := u.passwordHasher.Hash(temporary)
+	if err != nil {
+		return "", err
+	}
+	if err := u.userRepo.UpdatePassword(id, hashedPassword); err != nil {
+		return "", err
+	}
+
+	if err := u.sessionRepo.RevokeAllByUserID(id); err != nil {
+		return "", err
+	}
+	if err := u.refreshTokenRepo.RevokeAllByUserID(id); err != nil {
+		return "", err
+	}
+	return temporary, nil
+}
+
+// Unlock clears the failed logins counted against the user's name, lifting a
+// lockout or backoff. Lockouts of the client IP are left alone.
+func (u *adminUsecase) Unlock(id uint) error {
+	user, err := u.userRepo.GetByID(id)
+	if err != nil {
+		return err
+	}
+	return u.loginAttempts.Reset(domain.UsernameLoginKey(user.Username))
+}
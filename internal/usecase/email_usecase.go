@@ -0,0 +1,96 @@
+package usecase
+
+import (
+	"fmt"
+	"net/mail"
+	"strings"
+	"time"
+
+	"notes-app/internal/domain"
+	"notes-app/pkg/auth"
+	"notes-app/pkg/mailer"
+)
+
+type emailUsecase struct {
+	userRepo         domain.UserRepository
+	verificationRepo domain.EmailVerificationRepository
+	loginAttempts    domain.LoginAttemptTracker
+	hasher           domain.PasswordHasher
+	mailer           mailer.Mailer
+	verification     domain.EmailVerification
+}
+
+func NewEmailUsecase(userRepo domain.UserRepository, verificationRepo domain.EmailVerificationRepository, loginAttempts domain.LoginAttemptTracker,
+	hasher domain.PasswordHasher, m mailer.Mailer, verification domain.EmailVerification) domain.EmailUsecase {
+	return &emailUsecase{
+		userRepo:         userRepo,
+		verificationRepo: verificationRepo,
+		loginAttempts:    loginAttempts,
+		hasher:           hasher,
+		mailer:           m,
+		verification:     verification,
+	}
+}
+
+// ChangeEmail mails a verification token to email. The user's address, the one
+// password resets go to, only changes once the token is verified, so it always
+// belongs to someone who could read mail sent to it.
+func (u *emailUsecase) ChangeEmail(user *domain.User, email, currentPassword string) error {
+	email = strings.TrimSpace(email)
+	if !validEmail(email) {
+		return domain.ErrInvalidEmail
+	}
+	if err := confirmPassword(u.loginAttempts, u.hasher, user, currentPassword); err != nil {
+		return err
+	}
+	return u.SendVerification(user, email)
+}
+
+// SendVerification mails a verification token for email to the user, replacing
+// any token sent earlier. Register uses it for the address given at signup.
+func (u *emailUsecase) SendVerification(user *domain.User, email string) error {
+	token, err := auth.NewTokenID()
+	if err != nil {
+		return err
+	}
+	if err := u.verificationRepo.InvalidateAllByUserID(user.ID); err != nil {
+		return err
+	}
+	err = u.verificationRepo.Create(&domain.EmailVerificationToken{
+		UserID:    user.ID,
+		Email:     email,
+		TokenHash: auth.HashToken(token),
+		ExpiresAt: time.Now().Add(u.verification.TTL),
+	})
+	if err != nil {
+		return err
+	}
+
+	body := fmt.Sprintf("To use this address for the account %s, confirm it with the token\n\n%s\n\n", user.Username, token)
+	if u.verification.URL != "" {
+		body += fmt.Sprintf("or open %s%s.\n\n", u.verification.URL, token)
+	}
+	body += fmt.Sprintf("It expires in %s. If it was not you, ignore this email.", u.verification.TTL)
+
+	return u.mailer.Send(mailer.Message{
+		To:      email,
+		Subject: "Confirm your email address",
+		Body:    body,
+		Secrets: []string{token},
+	})
+}
+
+// VerifyEmail makes the address the token was mailed to the user's email.
+func (u *emailUsecase) VerifyEmail(token string) error {
+	verification, err := u.verificationRepo.Use(auth.HashToken(token))
+	if err != nil {
+		return err
+	}
+	return u.userRepo.SetVerifiedEmail(verification.UserID, verification.Email, time.Now())
+}
+
+// validEmail accepts a bare address such as "a@example.com", without a display name.
+func validEmail(email string) bool {
+	address, err := mail.ParseAddress(email)
+	return err == nil && address.Address == email
+}
@@ -0,0 +1,302 @@
+package usecase
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"notes-app/internal/domain"
+)
+
+const maxFilterLength = 1000
+
+// ParseNoteFilter parses a structured note filter such as
+//
+//	status:todo AND (updated_at>2026-01-01 OR tag:urgent) AND NOT title:~"draft"
+//
+// AND binds tighter than OR. Operators are ":" (equals), ":~" (contains,
+// case-insensitive), "!=", ">", ">=", "<" and "<=". Values are bare words or
+// double-quoted strings; time fields take a date (YYYY-MM-DD) or an RFC 3339
+// timestamp. An empty expression yields a nil filter.
+func ParseNoteFilter(input string) (domain.FilterExpr, error) {
+	p := &filterParser{input: []rune(input)}
+	if len(p.input) > maxFilterLength {
+		return nil, &domain.FilterSyntaxError{
+			Position: maxFilterLength + 1,
+			Message:  fmt.Sprintf("filter is longer than %d characters", maxFilterLength),
+		}
+	}
+
+	p.skipSpace()
+	if p.eof() {
+		return nil, nil
+	}
+
+	expr, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+
+	p.skipSpace()
+	if !p.eof() {
+		return nil, p.errorAt(p.pos, "expected AND, OR or end of filter, found %q", p.rest())
+	}
+	return expr, nil
+}
+
+type filterParser struct {
+	input []rune
+	pos   int
+}
+
+func (p *filterParser) parseOr() (domain.FilterExpr, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.keyword("OR") {
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = domain.FilterOr{Left: left, Right: right}
+	}
+	return left, nil
+}
+
+func (p *filterParser) parseAnd() (domain.FilterExpr, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.keyword("AND") {
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = domain.FilterAnd{Left: left, Right: right}
+	}
+	return left, nil
+}
+
+func (p *filterParser) parseUnary() (domain.FilterExpr, error) {
+	p.skipSpace()
+	if p.eof() {
+		return nil, p.errorAt(p.pos, "expected a condition")
+	}
+
+	if p.keyword("NOT") {
+		expr, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return domain.FilterNot{Expr: expr}, nil
+	}
+
+	if p.input[p.pos] == '(' {
+		open := p.pos
+		p.pos++
+		expr, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		p.skipSpace()
+		if p.eof() || p.input[p.pos] != ')' {
+			return nil, p.errorAt(open, "unclosed parenthesis")
+		}
+		p.pos++
+		return expr, nil
+	}
+
+	return p.parseCondition()
+}
+
+func (p *filterParser) parseCondition() (domain.FilterExpr, error) {
+	start := p.pos
+	for !p.eof() && (unicode.IsLetter(p.input[p.pos]) || unicode.IsDigit(p.input[p.pos]) || p.input[p.pos] == '_') {
+		p.pos++
+	}
+	field := string(p.input[start:p.pos])
+	if field == "" {
+		return nil, p.errorAt(start, "expected a field name, found %q", p.rest())
+	}
+	fieldType, ok := domain.NoteFilterFields[field]
+	if !ok {
+		return nil, p.errorAt(start, "unknown field %q", field)
+	}
+
+	p.skipSpace()
+	opStart := p.pos
+	op := p.readOperator()
+	if op == "" {
+		return nil, p.errorAt(opStart, "expected an operator after %q", field)
+	}
+	if !operatorSupported(fieldType, field, op) {
+		return nil, p.errorAt(opStart, "operator %q is not supported for field %q", op, field)
+	}
+
+	p.skipSpace()
+	valueStart := p.pos
+	raw, err := p.readValue()
+	if err != nil {
+		return nil, err
+	}
+
+	value, err := convertFilterValue(fieldType, raw)
+	if err != nil {
+		return nil, p.errorAt(valueStart, "%s for field %q", err.Error(), field)
+	}
+	if fieldType == domain.FilterLegacyIsDone {
+		return legacyIsDoneCondition(op, value.(domain.NoteStatus)), nil
+	}
+	return domain.FilterCondition{Field: field, Op: op, Value: value}, nil
+}
+
+// legacyIsDoneCondition rewrites is_done into a status condition: is_done:true
+// matches done notes and is_done:false every note that is not done, which is
+// what it meant before notes had more than two states.
+func legacyIsDoneCondition(op string, status domain.NoteStatus) domain.FilterExpr {
+	if status != domain.NoteStatusDone {
+		if op == domain.FilterEq {
+			op = domain.FilterNotEq
+		} else {
+			op = domain.FilterEq
+		}
+	}
+	return domain.FilterCondition{Field: "status", Op: op, Value: domain.NoteStatusDone}
+}
+
+func (p *filterParser) readOperator() string {
+	for _, op := range []string{
+		domain.FilterContains, domain.FilterGte, domain.FilterLte, domain.FilterNotEq,
+		domain.FilterEq, domain.FilterGt, domain.FilterLt,
+	} {
+		if strings.HasPrefix(string(p.input[p.pos:]), op) {
+			p.pos += len([]rune(op))
+			return op
+		}
+	}
+	return ""
+}
+
+func (p *filterParser) readValue() (string, error) {
+	start := p.pos
+	if p.eof() {
+		return "", p.errorAt(start, "expected a value")
+	}
+
+	if p.input[p.pos] == '"' {
+		var value strings.Builder
+		p.pos++
+		for !p.eof() {
+			ch := p.input[p.pos]
+			switch {
+			case ch == '\\' && p.pos+1 < len(p.input):
+				value.WriteRune(p.input[p.pos+1])
+				p.pos += 2
+			case ch == '"':
+				p.pos++
+				return value.String(), nil
+			default:
+				value.WriteRune(ch)
+				p.pos++
+			}
+		}
+		return "", p.errorAt(start, "unterminated quoted value")
+	}
+
+	for !p.eof() && !unicode.IsSpace(p.input[p.pos]) && p.input[p.pos] != '(' && p.input[p.pos] != ')' {
+		p.pos++
+	}
+	if p.pos == start {
+		return "", p.errorAt(start, "expected a value")
+	}
+	return string(p.input[start:p.pos]), nil
+}
+
+// keyword consumes kw (case-insensitive) when it is the next whole word.
+func (p *filterParser) keyword(kw string) bool {
+	p.skipSpace()
+	end := p.pos + len(kw)
+	if end > len(p.input) || !strings.EqualFold(string(p.input[p.pos:end]), kw) {
+		return false
+	}
+	if end < len(p.input) && !unicode.IsSpace(p.input[end]) && p.input[end] != '(' {
+		return false
+	}
+	p.pos = end
+	return true
+}
+
+func (p *filterParser) skipSpace() {
+	for !p.eof() && unicode.IsSpace(p.input[p.pos]) {
+		p.pos++
+	}
+}
+
+func (p *filterParser) eof() bool {
+	return p.pos >= len(p.input)
+}
+
+func (p *filterParser) rest() string {
+	rest := string(p.input[p.pos:])
+	if len(rest) > 20 {
+		rest = rest[:20] + "..."
+	}
+	return rest
+}
+
+func (p *filterParser) errorAt(pos int, format string, args ...interface{}) error {
+	return &domain.FilterSyntaxError{
+		Position: pos + 1,
+		Message:  fmt.Sprintf(format, args...),
+	}
+}
+
+func operatorSupported(fieldType domain.FilterFieldType, field, op string) bool {
+	switch {
+	case fieldType == domain.FilterString && field == "tag":
+		return op == domain.FilterEq || op == domain.FilterContains || op == domain.FilterNotEq
+	case fieldType == domain.FilterString:
+		return true
+	case fieldType == domain.FilterStatus, fieldType == domain.FilterLegacyIsDone:
+		return op == domain.FilterEq || op == domain.FilterNotEq
+	default:
+		return op != domain.FilterContains
+	}
+}
+
+func convertFilterValue(fieldType domain.FilterFieldType, raw string) (interface{}, error) {
+	switch fieldType {
+	case domain.FilterInt:
+		value, err := strconv.Atoi(raw)
+		if err != nil {
+			return nil, fmt.Errorf("expected a number, found %q", raw)
+		}
+		return value, nil
+	case domain.FilterStatus:
+		status := domain.NoteStatus(raw)
+		if !status.Valid() {
+			return nil, fmt.Errorf("expected todo, in_progress, done or archived, found %q", raw)
+		}
+		return status, nil
+	case domain.FilterLegacyIsDone:
+		status, ok := domain.StatusFromLegacyIsDone(raw)
+		if !ok {
+			return nil, fmt.Errorf("expected true or false, found %q", raw)
+		}
+		return status, nil
+	case domain.FilterTime:
+		if day, err := time.Parse("2006-01-02", raw); err == nil {
+			return domain.FilterTimeRange{Start: day, End: day.AddDate(0, 0, 1)}, nil
+		}
+		if ts, err := time.Parse(time.RFC3339, raw); err == nil {
+			return domain.FilterTimeRange{Start: ts, End: ts}, nil
+		}
+		return nil, fmt.Errorf("expected a date (YYYY-MM-DD) or RFC 3339 timestamp, found %q", raw)
+	default:
+		return raw, nil
+	}
+}
@@ -0,0 +1,119 @@
+package usecase
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"notes-app/internal/domain"
+)
+
+func cond(field, op string, value interface{}) domain.FilterCondition {
+	return domain.FilterCondition{Field: field, Op: op, Value: value}
+}
+
+func TestParseNoteFilter(t *testing.T) {
+	day := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	a := cond("title", domain.FilterEq, "a")
+	b := cond("title", domain.FilterEq, "b")
+	c := cond("title", domain.FilterEq, "c")
+
+	tests := []struct {
+		name  string
+		input string
+		want  domain.FilterExpr
+	}{
+		{"empty", "   ", nil},
+		{"single condition", "status:todo", cond("status", domain.FilterEq, domain.NoteStatusTodo)},
+		{"AND binds tighter than OR", "title:a OR title:b AND title:c",
+			domain.FilterOr{Left: a, Right: domain.FilterAnd{Left: b, Right: c}}},
+		{"AND before OR", "title:a AND title:b OR title:c",
+			domain.FilterOr{Left: domain.FilterAnd{Left: a, Right: b}, Right: c}},
+		{"parentheses override precedence", "(title:a OR title:b) AND title:c",
+			domain.FilterAnd{Left: domain.FilterOr{Left: a, Right: b}, Right: c}},
+		{"AND is left associative", "title:a AND title:b AND title:c",
+			domain.FilterAnd{Left: domain.FilterAnd{Left: a, Right: b}, Right: c}},
+		{"NOT binds tighter than AND", "NOT title:a AND title:b",
+			domain.FilterAnd{Left: domain.FilterNot{Expr: a}, Right: b}},
+		{"NOT of a group", "NOT (title:a OR title:b)",
+			domain.FilterNot{Expr: domain.FilterOr{Left: a, Right: b}}},
+		{"keywords are case-insensitive", "title:a and not title:b",
+			domain.FilterAnd{Left: a, Right: domain.FilterNot{Expr: b}}},
+		{"quoted value with spaces", `title:~"weekly meeting"`, cond("title", domain.FilterContains, "weekly meeting")},
+		{"quoted value with escapes", `title:"say \"hi\" \\ bye"`, cond("title", domain.FilterEq, `say "hi" \ bye`)},
+		{"quoted keyword is a value", `title:"AND"`, cond("title", domain.FilterEq, "AND")},
+		{"integer field", "version>=3", cond("version", domain.FilterGte, 3)},
+		{"date covers the day", "updated_at>2026-01-01",
+			cond("updated_at", domain.FilterGt, domain.FilterTimeRange{Start: day, End: day.AddDate(0, 0, 1)})},
+		{"timestamp is exact", "created_at<2026-01-01T00:00:00Z",
+			cond("created_at", domain.FilterLt, domain.FilterTimeRange{Start: day, End: day})},
+		{"tag not equal", "tag!=urgent", cond("tag", domain.FilterNotEq, "urgent")},
+		{"is_done:true is status:done", "is_done:true", cond("status", domain.FilterEq, domain.NoteStatusDone)},
+		{"is_done:false is status!=done", "is_done:false", cond("status", domain.FilterNotEq, domain.NoteStatusDone)},
+		{"is_done!=false is status:done", "is_done!=false", cond("status", domain.FilterEq, domain.NoteStatusDone)},
+		{"is_done!=yes is status!=done", "is_done!=yes", cond("status", domain.FilterNotEq, domain.NoteStatusDone)},
+		{"is_done in an expression", "is_done:false AND updated_at>2026-01-01",
+			domain.FilterAnd{
+				Left:  cond("status", domain.FilterNotEq, domain.NoteStatusDone),
+				Right: cond("updated_at", domain.FilterGt, domain.FilterTimeRange{Start: day, End: day.AddDate(0, 0, 1)}),
+			}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseNoteFilter(tt.input)
+			if err != nil {
+				t.Fatalf("ParseNoteFilter(%q): %v", tt.input, err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseNoteFilter(%q)\n got  %#v\n want %#v", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseNoteFilterErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		position int
+		message  string
+	}{
+		{"unknown field", `titel:~"meeting"`, 1, `unknown field "titel"`},
+		{"missing field", ":done", 1, "expected a field name"},
+		{"missing operator", "title", 6, `expected an operator after "title"`},
+		{"missing value", "title:", 7, "expected a value"},
+		{"contains on status", "status:~done", 7, `operator ":~" is not supported for field "status"`},
+		{"comparison on tag", "tag>urgent", 4, `operator ">" is not supported for field "tag"`},
+		{"contains on a time", "created_at:~2026", 11, `operator ":~" is not supported for field "created_at"`},
+		{"contains on is_done", "is_done:~true", 8, `operator ":~" is not supported for field "is_done"`},
+		{"comparison on is_done", "is_done>false", 8, `operator ">" is not supported for field "is_done"`},
+		{"bad status", "status:finished", 8, "expected todo, in_progress, done or archived"},
+		{"bad is_done", "is_done:maybe", 9, "expected true or false"},
+		{"bad number", "version:abc", 9, "expected a number"},
+		{"bad date", "updated_at>yesterday", 12, "expected a date"},
+		{"unterminated quote", `title:"abc`, 7, "unterminated quoted value"},
+		{"unclosed parenthesis", "(title:a OR title:b", 1, "unclosed parenthesis"},
+		{"dangling AND", "title:a AND", 12, "expected a condition"},
+		{"missing connective", "title:a title:b", 9, "expected AND, OR or end of filter"},
+		{"stray closing parenthesis", "title:a)", 8, "expected AND, OR or end of filter"},
+		{"error inside a group", "title:a AND (status:x)", 21, "expected todo"},
+		{"too long", strings.Repeat("a", maxFilterLength+1), maxFilterLength + 1, "longer than"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseNoteFilter(tt.input)
+			var syntaxErr *domain.FilterSyntaxError
+			if !errors.As(err, &syntaxErr) {
+				t.Fatalf("ParseNoteFilter(%q) = %v, want a *FilterSyntaxError", tt.input, err)
+			}
+			if syntaxErr.Position != tt.position {
+				t.Errorf("Position = %d, want %d (%s)", syntaxErr.Position, tt.position, syntaxErr.Message)
+			}
+			if !strings.Contains(syntaxErr.Message, tt.message) {
+				t.Errorf("Message = %q, want it to contain %q", syntaxErr.Message, tt.message)
+			}
+		})
+	}
+}
@@ -1,19 +1,44 @@
 package usecase
 
-import "notes-app/internal/domain"
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"notes-app/internal/domain"
+	"notes-app/pkg/diff"
+)
 
 type noteUsecase struct {
-	noteRepo domain.NoteRepository
+	noteRepo          domain.NoteRepository
+	revisionRetention domain.RevisionRetention
+	transitions       domain.NoteTransitions
 }
 
-func NewNoteUsecase(repo domain.NoteRepository) domain.NoteUsecase {
+func NewNoteUsecase(repo domain.NoteRepository, retention domain.RevisionRetention, transitions domain.NoteTransitions) domain.NoteUsecase {
 	return &noteUsecase{
-		noteRepo: repo,
+		noteRepo:          repo,
+		revisionRetention: retention,
+		transitions:       transitions,
 	}
 }
 
 func (u *noteUsecase) Create(note *domain.Note, user *domain.User) error {
+	if note.Status == "" {
+		note.Status = domain.NoteStatusTodo
+	}
+	if !note.Status.Valid() {
+		return domain.ErrInvalidStatus
+	}
+
 	note.UserID = user.ID
+	note.Version = 0
+	note.CompletedAt = nil
+	if note.Status == domain.NoteStatusDone {
+		now := time.Now()
+		note.CompletedAt = &now
+	}
 	return u.noteRepo.Create(note)
 }
 
@@ -21,18 +46,233 @@ func (u *noteUsecase) GetByID(id uint, user *domain.User) (*domain.Note, error)
 	return u.noteRepo.GetByID(id, user.ID)
 }
 
-func (u *noteUsecase) GetAll(user *domain.User) ([]domain.Note, error) {
-	return u.noteRepo.GetAllByUserID(user.ID)
+func (u *noteUsecase) GetAll(user *domain.User, filter domain.NoteFilter, opts domain.NoteListOptions) (domain.Page[domain.Note], error) {
+	opts, err := normalizeListOptions(opts, domain.NoteSortCreatedAt, domain.NoteFields)
+	if err != nil {
+		return domain.Page[domain.Note]{}, err
+	}
+	if opts.SortBy == domain.NoteSortRank {
+		return domain.Page[domain.Note]{}, domain.ErrInvalidSort
+	}
+
+	expr, err := ParseNoteFilter(filter.Expression)
+	if err != nil {
+		return domain.Page[domain.Note]{}, err
+	}
+	filter.Expr = expr
+	filter.Tags = normalizeTagNames(filter.Tags)
+
+	return u.noteRepo.GetAllByUserID(user.ID, filter, opts)
+}
+
+// normalizeTagNames trims the names the way tags are stored and drops empty and
+// repeated ones, so that match=all compares against the number of distinct tags.
+func normalizeTagNames(names []string) []string {
+	var normalized []string
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if name != "" && !contains(normalized, name) {
+			normalized = append(normalized, name)
+		}
+	}
+	return normalized
 }
 
 func (u *noteUsecase) Update(note *domain.Note, user *domain.User) error {
-	return u.noteRepo.Update(note, user.ID)
+	if note.Status != "" {
+		if err := u.applyTransition(note, user); err != nil {
+			return err
+		}
+	}
+
+	if err := u.noteRepo.Update(note, user.ID); err != nil {
+		return err
+	}
+	u.pruneRevisions(note.ID)
+	return nil
+}
+
+func (u *noteUsecase) Transition(id uint, status domain.NoteStatus, version int, user *domain.User) (*domain.Note, error) {
+	note := &domain.Note{ID: id, Status: status, Version: version}
+	if err := u.Update(note, user); err != nil {
+		return nil, err
+	}
+	return note, nil
+}
+
+// applyTransition checks note.Status against the configured transitions from
+// the stored status and stamps CompletedAt. When the caller sent no version the
+// one the check was made against is used, so a concurrent status change makes
+// the update fail instead of slipping past the check.
+func (u *noteUsecase) applyTransition(note *domain.Note, user *domain.User) error {
+	if !note.Status.Valid() {
+		return domain.ErrInvalidStatus
+	}
+
+	current, err := u.noteRepo.GetByID(note.ID, user.ID)
+	if err != nil {
+		return err
+	}
+	if !u.transitions.Allows(current.Status, note.Status) {
+		return fmt.Errorf("%w: %s to %s", domain.ErrTransitionNotAllowed, current.Status, note.Status)
+	}
+	if note.Version == 0 {
+		note.Version = current.Version
+	}
+
+	switch {
+	case note.Status != domain.NoteStatusDone:
+		note.CompletedAt = nil
+	case current.Status == domain.NoteStatusDone:
+		note.CompletedAt = current.CompletedAt
+	default:
+		now := time.Now()
+		note.CompletedAt = &now
+	}
+	return nil
+}
+
+func (u *noteUsecase) Delete(id uint, version int, user *domain.User) error {
+	return u.noteRepo.Delete(id, user.ID, version)
+}
+
+func (u *noteUsecase) Query(query string, user *domain.User, opts domain.NoteListOptions) (domain.Page[domain.NoteSearchResult], error) {
+	if strings.TrimSpace(query) == "" {
+		return domain.Page[domain.NoteSearchResult]{}, domain.ErrEmptySearchQuery
+	}
+
+	opts, err := normalizeListOptions(opts, domain.NoteSortRank, searchResultFields)
+	if err != nil {
+		return domain.Page[domain.NoteSearchResult]{}, err
+	}
+	return u.noteRepo.Query(query, user.ID, opts)
+}
+
+func (u *noteUsecase) CountQueries(queries []string, user *domain.User) ([]int64, error) {
+	for _, query := range queries {
+		if strings.TrimSpace(query) == "" {
+			return nil, domain.ErrEmptySearchQuery
+		}
+	}
+	return u.noteRepo.CountQueries(queries, user.ID)
+}
+
+func (u *noteUsecase) AttachTags(noteID uint, tagIDs []uint, user *domain.User) (*domain.Note, error) {
+	if err := u.noteRepo.AttachTags(noteID, user.ID, tagIDs); err != nil {
+		return nil, err
+	}
+	return u.noteRepo.GetByID(noteID, user.ID)
+}
+
+func (u *noteUsecase) DetachTag(noteID, tagID uint, user *domain.User) (*domain.Note, error) {
+	if err := u.noteRepo.DetachTag(noteID, user.ID, tagID); err != nil {
+		return nil, err
+	}
+	return u.noteRepo.GetByID(noteID, user.ID)
+}
+
+func (u *noteUsecase) Move(noteID uint, notebookID *uint, user *domain.User) (*domain.Note, error) {
+	if err := u.noteRepo.Move(noteID, user.ID, notebookID); err != nil {
+		return nil, err
+	}
+	return u.noteRepo.GetByID(noteID, user.ID)
+}
+
+func (u *noteUsecase) GetTrash(user *domain.User, page domain.PageRequest) (domain.Page[domain.Note], error) {
+	return u.noteRepo.GetTrashByUserID(user.ID, page.Normalized())
 }
 
-func (u *noteUsecase) Delete(id uint, user *domain.User) error {
-	return u.noteRepo.Delete(id, user.ID)
+func (u *noteUsecase) Restore(id uint, user *domain.User) (*domain.Note, error) {
+	if err := u.noteRepo.Restore(id, user.ID); err != nil {
+		return nil, err
+	}
+	return u.noteRepo.GetByID(id, user.ID)
+}
+
+func (u *noteUsecase) Purge(id uint, user *domain.User) error {
+	return u.noteRepo.Purge(id, user.ID)
+}
+
+func (u *noteUsecase) GetRevisions(noteID uint, user *domain.User, page domain.PageRequest) (domain.Page[domain.NoteRevision], error) {
+	if _, err := u.noteRepo.GetByID(noteID, user.ID); err != nil {
+		return domain.Page[domain.NoteRevision]{}, err
+	}
+	return u.noteRepo.GetRevisions(noteID, user.ID, page.Normalized())
+}
+
+func (u *noteUsecase) GetRevision(noteID uint, revision int, user *domain.User) (*domain.NoteRevision, error) {
+	if _, err := u.noteRepo.GetByID(noteID, user.ID); err != nil {
+		return nil, err
+	}
+	return u.noteRepo.GetRevision(noteID, user.ID, revision)
 }
 
-func (u *noteUsecase) Query(query string, user *domain.User) ([]domain.Note, error) {
-	return u.noteRepo.Query(query, user.ID)
+func (u *noteUsecase) DiffRevisions(noteID uint, from, to int, user *domain.User) (*domain.RevisionDiff, error) {
+	fromRev, err := u.GetRevision(noteID, from, user)
+	if err != nil {
+		return nil, err
+	}
+	toRev, err := u.noteRepo.GetRevision(noteID, user.ID, to)
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.RevisionDiff{
+		NoteID:    noteID,
+		From:      from,
+		To:        to,
+		NoteTitle: diff.Lines(fromRev.NoteTitle, toRev.NoteTitle),
+		Content:   diff.Lines(fromRev.Content, toRev.Content),
+		Status:    diff.Lines(string(fromRev.Status), string(toRev.Status)),
+	}, nil
+}
+
+func (u *noteUsecase) RestoreRevision(noteID uint, revision int, user *domain.User) (*domain.Note, error) {
+	if _, err := u.noteRepo.GetByID(noteID, user.ID); err != nil {
+		return nil, err
+	}
+	if err := u.noteRepo.RestoreRevision(noteID, user.ID, revision); err != nil {
+		return nil, err
+	}
+	u.pruneRevisions(noteID)
+	return u.noteRepo.GetByID(noteID, user.ID)
+}
+
+// pruneRevisions applies the retention policy; a failure here must not fail the edit itself.
+func (u *noteUsecase) pruneRevisions(noteID uint) {
+	if err := u.noteRepo.PruneRevisions(noteID, u.revisionRetention); err != nil {
+		log.Printf("Failed to prune revisions for note %d: %v", noteID, err)
+	}
+}
+
+// searchResultFields are the selectable fields of a search result.
+var searchResultFields = append([]string{"rank", "snippet"}, domain.NoteFields...)
+
+// normalizeListOptions validates sort and field selection, applying defaultSort
+// when none was requested, and clamps the page size.
+func normalizeListOptions(opts domain.NoteListOptions, defaultSort string, fields []string) (domain.NoteListOptions, error) {
+	switch opts.SortBy {
+	case "":
+		opts.SortBy = defaultSort
+	case domain.NoteSortCreatedAt, domain.NoteSortUpdatedAt, domain.NoteSortTitle, domain.NoteSortRank:
+	default:
+		return opts, domain.ErrInvalidSort
+	}
+	for _, field := range opts.Fields {
+		if !contains(fields, field) {
+			return opts, fmt.Errorf("%w: %s", domain.ErrUnknownField, field)
+		}
+	}
+
+	opts.Page = opts.Page.Normalized()
+	return opts, nil
+}
+
+func contains(values []string, target string) bool {
+	for _, v := range values {
+		if v == target {
+			return true
+		}
+	}
+	return false
 }
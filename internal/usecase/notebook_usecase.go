@@ -0,0 +1,105 @@
+package usecase
+
+import (
+	"strings"
+
+	"notes-app/internal/domain"
+)
+
+type notebookUsecase struct {
+	notebookRepo domain.NotebookRepository
+}
+
+func NewNotebookUsecase(repo domain.NotebookRepository) domain.NotebookUsecase {
+	return &notebookUsecase{
+		notebookRepo: repo,
+	}
+}
+
+func (u *notebookUsecase) Create(notebook *domain.Notebook, user *domain.User) error {
+	notebook.Name = strings.TrimSpace(notebook.Name)
+	if notebook.Name == "" {
+		return domain.ErrNotebookNameEmpty
+	}
+
+	notebook.ID = 0
+	notebook.UserID = user.ID
+	return u.notebookRepo.Create(notebook)
+}
+
+func (u *notebookUsecase) GetAll(user *domain.User, page domain.PageRequest) (domain.Page[domain.Notebook], error) {
+	return u.notebookRepo.GetAllByUserID(user.ID, page.Normalized())
+}
+
+func (u *notebookUsecase) GetTree(id uint, user *domain.User) (*domain.NotebookTree, error) {
+	notebooks, notes, err := u.notebookRepo.GetSubtree(id, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	children := make(map[uint][]domain.Notebook)
+	var root domain.Notebook
+	for _, nb := range notebooks {
+		if nb.ID == id {
+			root = nb
+		} else if nb.ParentID != nil {
+			children[*nb.ParentID] = append(children[*nb.ParentID], nb)
+		}
+	}
+
+	notesByNotebook := make(map[uint][]domain.Note)
+	for _, note := range notes {
+		notesByNotebook[*note.NotebookID] = append(notesByNotebook[*note.NotebookID], note)
+	}
+
+	tree := buildNotebookTree(root, children, notesByNotebook)
+	return &tree, nil
+}
+
+func (u *notebookUsecase) Rename(id uint, name string, user *domain.User) (*domain.Notebook, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, domain.ErrNotebookNameEmpty
+	}
+
+	if err := u.notebookRepo.Rename(id, user.ID, name); err != nil {
+		return nil, err
+	}
+	return u.notebookRepo.GetByID(id, user.ID)
+}
+
+func (u *notebookUsecase) Move(id uint, parentID *uint, user *domain.User) (*domain.Notebook, error) {
+	if parentID != nil && *parentID == id {
+		return nil, domain.ErrNotebookCycle
+	}
+
+	if err := u.notebookRepo.Move(id, user.ID, parentID); err != nil {
+		return nil, err
+	}
+	return u.notebookRepo.GetByID(id, user.ID)
+}
+
+func (u *notebookUsecase) Delete(id uint, mode string, user *domain.User) error {
+	if mode == "" {
+		mode = domain.NotebookDeleteRehome
+	}
+	if mode != domain.NotebookDeleteCascade && mode != domain.NotebookDeleteRehome {
+		return domain.ErrInvalidDeleteMode
+	}
+	return u.notebookRepo.Delete(id, user.ID, mode)
+}
+
+func buildNotebookTree(nb domain.Notebook, children map[uint][]domain.Notebook, notes map[uint][]domain.Note) domain.NotebookTree {
+	tree := domain.NotebookTree{
+		Notebook: nb,
+		Children: []domain.NotebookTree{},
+		Notes:    notes[nb.ID],
+	}
+	if tree.Notes == nil {
+		tree.Notes = []domain.Note{}
+	}
+	for _, child := range children[nb.ID] {
+		tree.Children = append(tree.Children, buildNotebookTree(child, children, notes))
+	}
+	return tree
+}
@@ -0,0 +1,161 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"notes-app/internal/domain"
+	"notes-app/pkg/auth"
+	"notes-app/pkg/mailer"
+)
+
+type passwordUsecase struct {
+	userRepo         domain.UserRepository
+	resetRepo        domain.PasswordResetRepository
+	sessionRepo      domain.SessionRepository
+	refreshTokenRepo domain.RefreshTokenRepository
+	loginAttempts    domain.LoginAttemptTracker
+	policy           domain.PasswordPolicy
+	hasher           domain.PasswordHasher
+	mailer           mailer.Mailer
+	reset            domain.PasswordReset
+}
+
+func NewPasswordUsecase(userRepo domain.UserRepository, resetRepo domain.PasswordResetRepository, sessionRepo domain.SessionRepository,
+	refreshTokenRepo domain.RefreshTokenRepository, loginAttempts domain.LoginAttemptTracker, policy domain.PasswordPolicy, hasher domain.PasswordHasher,
+	m mailer.Mailer, reset domain.PasswordReset) domain.PasswordUsecase {
+	return &passwordUsecase{
+		userRepo:         userRepo,
+		resetRepo:        resetRepo,
+		sessionRepo:      sessionRepo,
+		refreshTokenRepo: refreshTokenRepo,
+		loginAttempts:    loginAttempts,
+		policy:           policy,
+		hasher:           hasher,
+		mailer:           m,
+		reset:            reset,
+	}
+}
+
+// ChangePassword keeps the session identified by currentSessionID logged in and
+// revokes every other one, so a stolen session does not outlive the change.
+func (u *passwordUsecase) ChangePassword(user *domain.User, currentPassword, newPassword, currentSessionID string) error {
+	if err := confirmPassword(u.loginAttempts, u.hasher, user, currentPassword); err != nil {
+		return err
+	}
+	if err := u.setPassword(user.ID, newPassword); err != nil {
+		return err
+	}
+
+	if err := u.sessionRepo.RevokeOthers(user.ID, currentSessionID); err != nil {
+		return err
+	}
+	return u.refreshTokenRepo.RevokeOthers(user.ID, currentSessionID)
+}
+
+// RequestReset mails a reset link to the user's verified email address. Unknown
+// users and users without one are not reported, so the endpoint cannot be used
+// to find out which accounts exist.
+func (u *passwordUsecase) RequestReset(username string) error {
+	user, err := u.userRepo.GetByUsername(username)
+	if err != nil {
+		if errors.Is(err, domain.ErrUserNotFound) {
+			return nil
+		}
+		return err
+	}
+	if user.Email == "" || user.EmailVerifiedAt == nil || user.DisabledAt != nil {
+		log.Printf("Password reset requested for user %d, who cannot receive one", user.ID)
+		return nil
+	}
+
+	token, err := auth.NewTokenID()
+	if err != nil {
+		return err
+	}
+	if err := u.resetRepo.InvalidateAllByUserID(user.ID); err != nil {
+		return err
+	}
+	err = u.resetRepo.Create(&domain.PasswordResetToken{
+		UserID:    user.ID,
+		TokenHash: auth.HashToken(token),
+		ExpiresAt: time.Now().Add(u.reset.TTL),
+	})
+	if err != nil {
+		return err
+	}
+
+	body := fmt.Sprintf("Someone asked to reset the password of %s. Your reset token is\n\n%s\n\n", user.Username, token)
+	if u.reset.URL != "" {
+		body += fmt.Sprintf("or open %s%s to choose a new password.\n\n", u.reset.URL, token)
+	}
+	body += fmt.Sprintf("It expires in %s. If it was not you, ignore this email.", u.reset.TTL)
+
+	return u.mailer.Send(mailer.Message{
+		To:      user.Email,
+		Subject: "Reset your password",
+		Body:    body,
+		Secrets: []string{token},
+	})
+}
+
+// ResetPassword sets a new password with a mailed token and logs the user out
+// everywhere.
+func (u *passwordUsecase) ResetPassword(token, newPassword string) error {
+	if err := u.policy.Validate(newPassword); err != nil {
+		return err
+	}
+
+	reset, err := u.resetRepo.Use(auth.HashToken(token))
+	if err != nil {
+		return err
+	}
+	if err := u.setPassword(reset.UserID, newPassword); err != nil {
+		return err
+	}
+
+	if err := u.sessionRepo.RevokeAllByUserID(reset.UserID); err != nil {
+		return err
+	}
+	return u.refreshTokenRepo.RevokeAllByUserID(reset.UserID)
+}
+
+func (u *passwordUsecase) setPassword(userID uint, password string) error {
+	if err := u.policy.Validate(password); err != nil {
+		return err
+	}
+
+	hashedPassword, err := u.hasher.Hash(password)
+	if err != nil {
+		return err
+	}
+	return u.userRepo.UpdatePassword(userID, hashedPassword)
+}
+
+// confirmPassword checks the password a logged-in user re-enters to confirm a
+// sensitive change. Failures count against the login lockout of the username,
+// so a stolen access token cannot be used to guess it.
+func confirmPassword(loginAttempts domain.LoginAttemptTracker, hasher domain.PasswordHasher, user *domain.User, password string) error {
+	key := domain.UsernameLoginKey(user.Username)
+	if err := loginAttempts.Check(key); err != nil {
+		return err
+	}
+
+	match, err := hasher.Verify(password, user.Password)
+	if err != nil {
+		return err
+	}
+	if !match {
+		if err := loginAttempts.RecordFailure(key); err != nil {
+			log.Printf("Failed to record failed password confirmation of user %d: %v", user.ID, err)
+		}
+		return domain.ErrWrongPassword
+	}
+
+	if err := loginAttempts.Reset(key); err != nil {
+		log.Printf("Failed to reset failed logins of user %d: %v", user.ID, err)
+	}
+	return nil
+}
@@ -0,0 +1,134 @@
+package usecase
+
+import (
+	"errors"
+	"strings"
+
+	"notes-app/internal/domain"
+)
+
+type savedSearchUsecase struct {
+	searchRepo  domain.SavedSearchRepository
+	noteUsecase domain.NoteUsecase
+}
+
+func NewSavedSearchUsecase(repo domain.SavedSearchRepository, nu domain.NoteUsecase) domain.SavedSearchUsecase {
+	return &savedSearchUsecase{
+		searchRepo:  repo,
+		noteUsecase: nu,
+	}
+}
+
+func (u *savedSearchUsecase) Create(search *domain.SavedSearch, user *domain.User) error {
+	if err := u.validate(search, user.ID); err != nil {
+		return err
+	}
+
+	search.ID = 0
+	search.UserID = user.ID
+	return u.searchRepo.Create(search)
+}
+
+func (u *savedSearchUsecase) GetByID(id uint, user *domain.User) (*domain.SavedSearch, error) {
+	return u.searchRepo.GetByID(id, user.ID)
+}
+
+func (u *savedSearchUsecase) GetAll(user *domain.User, page domain.PageRequest) (domain.Page[domain.SavedSearch], error) {
+	return u.searchRepo.GetAllByUserID(user.ID, page.Normalized())
+}
+
+func (u *savedSearchUsecase) Update(search *domain.SavedSearch, user *domain.User) error {
+	if err := u.validate(search, user.ID); err != nil {
+		return err
+	}
+	if err := u.searchRepo.Update(search, user.ID); err != nil {
+		return err
+	}
+
+	updated, err := u.searchRepo.GetByID(search.ID, user.ID)
+	if err != nil {
+		return err
+	}
+	*search = *updated
+	return nil
+}
+
+func (u *savedSearchUsecase) Delete(id uint, user *domain.User) error {
+	return u.searchRepo.Delete(id, user.ID)
+}
+
+func (u *savedSearchUsecase) Results(id uint, user *domain.User, page domain.PageRequest) (domain.Page[domain.NoteSearchResult], error) {
+	search, err := u.searchRepo.GetByID(id, user.ID)
+	if err != nil {
+		return domain.Page[domain.NoteSearchResult]{}, err
+	}
+
+	sortBy, descending := parseSavedSearchSort(search.Sort)
+	return u.noteUsecase.Query(search.Query, user, domain.NoteListOptions{
+		Page:       page,
+		SortBy:     sortBy,
+		Descending: descending,
+	})
+}
+
+// Counts returns the number of matching notes for every saved search of the user.
+func (u *savedSearchUsecase) Counts(user *domain.User) ([]domain.SavedSearchCount, error) {
+	searches, err := u.searchRepo.ListByUserID(user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	queries := make([]string, len(searches))
+	for i, search := range searches {
+		queries[i] = search.Query
+	}
+	counts, err := u.noteUsecase.CountQueries(queries, user)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]domain.SavedSearchCount, len(searches))
+	for i, search := range searches {
+		result[i] = domain.SavedSearchCount{ID: search.ID, Name: search.Name, Count: counts[i]}
+	}
+	return result, nil
+}
+
+func (u *savedSearchUsecase) validate(search *domain.SavedSearch, userID uint) error {
+	search.Name = strings.TrimSpace(search.Name)
+	search.Query = strings.TrimSpace(search.Query)
+	search.Sort = strings.TrimSpace(search.Sort)
+	if search.Name == "" || search.Query == "" {
+		return domain.ErrSavedSearchInvalid
+	}
+
+	if search.Sort != "" {
+		sortBy, _ := parseSavedSearchSort(search.Sort)
+		switch sortBy {
+		case domain.NoteSortCreatedAt, domain.NoteSortUpdatedAt, domain.NoteSortTitle, domain.NoteSortRank:
+		default:
+			return domain.ErrInvalidSort
+		}
+		if _, order, found := strings.Cut(search.Sort, ":"); found && order != "asc" && order != "desc" {
+			return domain.ErrInvalidSort
+		}
+	}
+
+	existing, err := u.searchRepo.GetByName(search.Name, userID)
+	if err != nil {
+		if errors.Is(err, domain.ErrSavedSearchNotFound) {
+			return nil
+		}
+		return err
+	}
+	if existing.ID != search.ID {
+		return domain.ErrSavedSearchNameTaken
+	}
+	return nil
+}
+
+// parseSavedSearchSort splits "field[:asc|desc]"; the order defaults to descending.
+func parseSavedSearchSort(sort string) (sortBy string, descending bool) {
+	sortBy, order, _ := strings.Cut(sort, ":")
+	return sortBy, order != "asc"
+}
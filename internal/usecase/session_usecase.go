@@ -0,0 +1,43 @@
+package usecase
+
+import (
+	"notes-app/internal/domain"
+)
+
+type sessionUsecase struct {
+	sessionRepo      domain.SessionRepository
+	refreshTokenRepo domain.RefreshTokenRepository
+}
+
+func NewSessionUsecase(repo domain.SessionRepository, refreshTokenRepo domain.RefreshTokenRepository) domain.SessionUsecase {
+	return &sessionUsecase{
+		sessionRepo:      repo,
+		refreshTokenRepo: refreshTokenRepo,
+	}
+}
+
+// GetAll lists the user's active sessions, flagging the one identified by
+// currentSessionID (the sid of the calling token).
+func (u *sessionUsecase) GetAll(user *domain.User, currentSessionID string, page domain.PageRequest) (domain.Page[domain.Session], error) {
+	sessions, err := u.sessionRepo.GetActiveByUserID(user.ID, page.Normalized())
+	if err != nil {
+		return sessions, err
+	}
+	for i := range sessions.Data {
+		sessions.Data[i].Current = sessions.Data[i].FamilyID == currentSessionID
+	}
+	return sessions, nil
+}
+
+func (u *sessionUsecase) Revoke(id uint, user *domain.User) error {
+	return revokeSession(u.sessionRepo, u.refreshTokenRepo, id, user.ID)
+}
+
+// revokeSession ends a session together with every refresh token issued for it.
+func revokeSession(sessions domain.SessionRepository, refreshTokens domain.RefreshTokenRepository, id, userID uint) error {
+	session, err := sessions.Revoke(id, userID)
+	if err != nil {
+		return err
+	}
+	return refreshTokens.RevokeFamily(session.FamilyID)
+}
@@ -0,0 +1,75 @@
+package usecase
+
+import (
+	"errors"
+	"strings"
+
+	"notes-app/internal/domain"
+)
+
+type tagUsecase struct {
+	tagRepo domain.TagRepository
+}
+
+func NewTagUsecase(repo domain.TagRepository) domain.TagUsecase {
+	return &tagUsecase{
+		tagRepo: repo,
+	}
+}
+
+func (u *tagUsecase) Create(tag *domain.Tag, user *domain.User) error {
+	tag.Name = strings.TrimSpace(tag.Name)
+	if tag.Name == "" {
+		return domain.ErrTagNameEmpty
+	}
+	if err := u.ensureNameAvailable(tag.Name, 0, user.ID); err != nil {
+		return err
+	}
+
+	tag.ID = 0
+	tag.UserID = user.ID
+	return u.tagRepo.Create(tag)
+}
+
+func (u *tagUsecase) GetByID(id uint, user *domain.User) (*domain.Tag, error) {
+	return u.tagRepo.GetByID(id, user.ID)
+}
+
+func (u *tagUsecase) GetAll(user *domain.User, page domain.PageRequest) (domain.Page[domain.Tag], error) {
+	return u.tagRepo.GetAllByUserID(user.ID, page.Normalized())
+}
+
+func (u *tagUsecase) Rename(id uint, name string, user *domain.User) (*domain.Tag, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, domain.ErrTagNameEmpty
+	}
+	if err := u.ensureNameAvailable(name, id, user.ID); err != nil {
+		return nil, err
+	}
+
+	if err := u.tagRepo.Update(&domain.Tag{ID: id, Name: name}, user.ID); err != nil {
+		return nil, err
+	}
+	return u.tagRepo.GetByID(id, user.ID)
+}
+
+func (u *tagUsecase) Delete(id uint, user *domain.User) error {
+	return u.tagRepo.Delete(id, user.ID)
+}
+
+// ensureNameAvailable is only a fast path for the common case; the repository
+// maps the unique index violation of a concurrent write to ErrTagNameTaken.
+func (u *tagUsecase) ensureNameAvailable(name string, exceptID, userID uint) error {
+	existing, err := u.tagRepo.GetByName(name, userID)
+	if err != nil {
+		if errors.Is(err, domain.ErrTagNotFound) {
+			return nil
+		}
+		return err
+	}
+	if existing.ID != exceptID {
+		return domain.ErrTagNameTaken
+	}
+	return nil
+}
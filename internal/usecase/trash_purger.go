@@ -0,0 +1,61 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"notes-app/internal/domain"
+)
+
+// TrashPurger permanently removes notes that have been in the trash longer than the retention period.
+type TrashPurger struct {
+	noteRepo  domain.NoteRepository
+	retention time.Duration
+	interval  time.Duration
+}
+
+// NewTrashPurger rejects a non-positive interval, which time.NewTicker would
+// panic on, and a negative retention, which would purge notes as soon as they
+// are trashed.
+func NewTrashPurger(repo domain.NoteRepository, retention, interval time.Duration) (*TrashPurger, error) {
+	if interval <= 0 {
+		return nil, fmt.Errorf("purge interval must be positive, got %s", interval)
+	}
+	if retention < 0 {
+		return nil, fmt.Errorf("retention must not be negative, got %s", retention)
+	}
+	return &TrashPurger{
+		noteRepo:  repo,
+		retention: retention,
+		interval:  interval,
+	}, nil
+}
+
+// Run purges once immediately and then on every interval until ctx is cancelled.
+func (p *TrashPurger) Run(ctx context.Context) {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		p.purge()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (p *TrashPurger) purge() {
+	purged, err := p.noteRepo.PurgeDeletedBefore(time.Now().Add(-p.retention))
+	if err != nil {
+		log.Printf("Trash purge failed: %v", err)
+		return
+	}
+	if purged > 0 {
+		log.Printf("Purged %d notes from trash", purged)
+	}
+}
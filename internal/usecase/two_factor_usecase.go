@@ -0,0 +1,137 @@
+package usecase
+
+import (
+	"crypto/rand"
+	"encoding/base32"
+	"errors"
+	"strings"
+	"time"
+
+	"notes-app/internal/domain"
+	"notes-app/pkg/auth"
+	"notes-app/pkg/totp"
+)
+
+const (
+	recoveryCodeCount = 10
+
+	// totpSkew accepts codes one step either side of the current one.
+	totpSkew = 1
+)
+
+type twoFactorUsecase struct {
+	twoFactorRepo domain.TwoFactorRepository
+	issuer        string
+	now           func() time.Time
+}
+
+// NewTwoFactorUsecase shows issuer in authenticator apps. now is the clock codes
+// are checked against; pass time.Now outside of tests.
+func NewTwoFactorUsecase(repo domain.TwoFactorRepository, issuer string, now func() time.Time) domain.TwoFactorUsecase {
+	return &twoFactorUsecase{
+		twoFactorRepo: repo,
+		issuer:        issuer,
+		now:           now,
+	}
+}
+
+// Setup generates a new pending secret, replacing any earlier pending one.
+func (u *twoFactorUsecase) Setup(user *domain.User) (*domain.TwoFactorSetup, error) {
+	existing, err := u.twoFactorRepo.Get(user.ID)
+	if err == nil && existing.EnabledAt != nil {
+		return nil, domain.ErrTwoFactorAlreadyActive
+	}
+	if err != nil && !errors.Is(err, domain.ErrTwoFactorNotSetUp) {
+		return nil, err
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return nil, err
+	}
+	if err := u.twoFactorRepo.Save(&domain.TwoFactor{UserID: user.ID, Secret: secret}); err != nil {
+		return nil, err
+	}
+
+	return &domain.TwoFactorSetup{
+		Secret: secret,
+		URI:    totp.URI(u.issuer, user.Username, secret),
+	}, nil
+}
+
+// Enable activates the pending secret once the user proves their app produces
+// valid codes, and returns the recovery codes, which are only shown now.
+func (u *twoFactorUsecase) Enable(user *domain.User, code string) ([]string, error) {
+	twoFactor, err := u.twoFactorRepo.Get(user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if twoFactor.EnabledAt != nil {
+		return nil, domain.ErrTwoFactorAlreadyActive
+	}
+
+	step, ok := totp.Verify(twoFactor.Secret, code, u.now(), totpSkew)
+	if !ok {
+		return nil, domain.ErrTwoFactorCodeInvalid
+	}
+
+	codes := make([]string, recoveryCodeCount)
+	hashes := make([]string, recoveryCodeCount)
+	for i := range codes {
+		if codes[i], err = newRecoveryCode(); err != nil {
+			return nil, err
+		}
+		hashes[i] = auth.HashToken(codes[i])
+	}
+
+	if err := u.twoFactorRepo.Enable(user.ID, step, hashes); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+func (u *twoFactorUsecase) Disable(user *domain.User, code string) error {
+	if err := u.Authenticate(user.ID, code); err != nil {
+		return err
+	}
+	return u.twoFactorRepo.Delete(user.ID)
+}
+
+func (u *twoFactorUsecase) IsEnabled(userID uint) (bool, error) {
+	twoFactor, err := u.twoFactorRepo.Get(userID)
+	if errors.Is(err, domain.ErrTwoFactorNotSetUp) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return twoFactor.EnabledAt != nil, nil
+}
+
+// Authenticate accepts either a current TOTP code or an unused recovery code,
+// consuming it.
+func (u *twoFactorUsecase) Authenticate(userID uint, code string) error {
+	twoFactor, err := u.twoFactorRepo.Get(userID)
+	if err != nil {
+		return err
+	}
+	if twoFactor.EnabledAt == nil {
+		return domain.ErrTwoFactorNotSetUp
+	}
+
+	code = strings.TrimSpace(code)
+	if step, ok := totp.Verify(twoFactor.Secret, code, u.now(), totpSkew); ok {
+		return u.twoFactorRepo.UseStep(userID, step)
+	}
+	return u.twoFactorRepo.UseRecoveryCode(userID, auth.HashToken(strings.ToUpper(code)))
+}
+
+// newRecoveryCode returns a code such as "7KQ2M-XD4PA".
+func newRecoveryCode() (string, error) {
+	b := make([]byte, 7)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	code := base32.StdEncoding.EncodeToString(b)[:10]
+	return code[:5] + "-" + code[5:], nil
+}
@@ -6,79 +6,372 @@ import (
 	"notes-app/internal/domain"
 	"notes-app/pkg/auth"
 	"notes-app/pkg/types"
-
-	"golang.org/x/crypto/bcrypt"
+	"strings"
+	"time"
 )
 
 type userUsecase struct {
-	userRepo domain.UserRepository
+	userRepo         domain.UserRepository
+	refreshTokenRepo domain.RefreshTokenRepository
+	sessionRepo      domain.SessionRepository
+	twoFactor        domain.TwoFactorUsecase
+	emails           domain.EmailUsecase
+	loginAttempts    domain.LoginAttemptTracker
+	passwordPolicy   domain.PasswordPolicy
+	passwordHasher   domain.PasswordHasher
+	adminUsernames   []string
 }
 
-func NewUserUsecase(repo domain.UserRepository) domain.UserUsecase {
+// NewUserUsecase makes the first registered user and every user named in
+// adminUsernames an administrator. emails verifies the address given at signup.
+func NewUserUsecase(repo domain.UserRepository, refreshTokenRepo domain.RefreshTokenRepository, sessionRepo domain.SessionRepository,
+	twoFactor domain.TwoFactorUsecase, emails domain.EmailUsecase, loginAttempts domain.LoginAttemptTracker, passwordPolicy domain.PasswordPolicy, passwordHasher domain.PasswordHasher, adminUsernames []string) domain.UserUsecase {
 	return &userUsecase{
-		userRepo: repo,
+		userRepo:         repo,
+		refreshTokenRepo: refreshTokenRepo,
+		sessionRepo:      sessionRepo,
+		twoFactor:        twoFactor,
+		emails:           emails,
+		loginAttempts:    loginAttempts,
+		passwordPolicy:   passwordPolicy,
+		passwordHasher:   passwordHasher,
+		adminUsernames:   adminUsernames,
 	}
 }
 
 func (u *userUsecase) Register(user *domain.User) error {
-	// Log original password (remove in production)
-	log.Printf("Registering user - Password before hash length: %d", len(user.Password))
+	user.Username = strings.TrimSpace(user.Username)
+	if user.Username == "" {
+		return domain.ErrUsernameRequired
+	}
+	user.Email = strings.TrimSpace(user.Email)
+	if user.Email != "" && !validEmail(user.Email) {
+		return domain.ErrInvalidEmail
+	}
+	if err := u.passwordPolicy.Validate(user.Password); err != nil {
+		return err
+	}
 
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(user.Password), bcrypt.DefaultCost)
+	hashedPassword, err := u.passwordHasher.Hash(user.Password)
 	if err != nil {
 		return err
 	}
 
-	// Log hashed password (remove in production)
-	log.Printf("Generated hash length: %d", len(hashedPassword))
+	user.Password = hashedPassword
+	user.EmailVerifiedAt = nil
+	user.DisabledAt = nil
+	user.Role = domain.RoleUser
+	if contains(u.adminUsernames, user.Username) {
+		user.Role = domain.RoleAdmin
+	}
+	// The repository makes the very first account an admin as well
+	if err := u.userRepo.Create(user); err != nil {
+		return err
+	}
 
-	user.Password = string(hashedPassword)
-	return u.userRepo.Create(user)
+	// Password resets only go to a verified address. The account exists either
+	// way; a lost mail can be sent again through PUT /me/email.
+	if user.Email != "" {
+		if err := u.emails.SendVerification(user, user.Email); err != nil {
+			log.Printf("Failed to send the verification mail of user %d: %v", user.ID, err)
+		}
+	}
+	return nil
 }
 
-func (u *userUsecase) Login(username, password string) (*types.TokenPair, error) {
-	log.Printf("Login usecase - Username: %s, Password length: %d",
-		username, len(password))
+// BootstrapAdmins promotes the configured admin usernames that already have an
+// account, for deployments whose first user predates roles.
+func (u *userUsecase) BootstrapAdmins() error {
+	promoted, err := u.userRepo.PromoteToAdmin(u.adminUsernames)
+	if err != nil {
+		return err
+	}
+	if promoted > 0 {
+		log.Printf("Promoted %d configured users to admin", promoted)
+	}
+	return nil
+}
+
+func (u *userUsecase) Login(username, password string, client domain.ClientInfo) (*domain.LoginResult, error) {
+	// The username key does not depend on the client address, so rotating IPs
+	// only escapes the per-IP limit, never the account lockout.
+	attemptKeys := []domain.LoginAttemptKey{domain.UsernameLoginKey(username), domain.IPLoginKey(client.IPAddress)}
+	if err := u.checkLoginAttempts(attemptKeys); err != nil {
+		return nil, err
+	}
 
 	user, err := u.userRepo.GetByUsername(username)
 	if err != nil {
 		log.Printf("Error finding user: %v", err)
-		return nil, errors.New("invalid credentials")
+		u.recordLoginFailure(attemptKeys)
+		return nil, domain.ErrInvalidCredentials
 	}
 
-	log.Printf("Found user with ID: %d, Stored password hash length: %d", user.ID, len(user.Password))
-
 	// Compare passwords
-	err = bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password))
+	match, err := u.passwordHasher.Verify(password, user.Password)
 	if err != nil {
 		log.Printf("Password comparison failed: %v", err)
-		return nil, errors.New("invalid credentials")
+	}
+	if !match {
+		u.recordLoginFailure(attemptKeys)
+		return nil, domain.ErrInvalidCredentials
+	}
+	if user.DisabledAt != nil {
+		return nil, domain.ErrUserDisabled
+	}
+	u.upgradePasswordHash(user, password)
+
+	twoFactorEnabled, err := u.twoFactor.IsEnabled(user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if twoFactorEnabled {
+		challenge, err := auth.GenerateChallengeToken(user.ID)
+		if err != nil {
+			return nil, err
+		}
+		return &domain.LoginResult{TwoFactorRequired: true, ChallengeToken: challenge}, nil
+	}
+
+	tokens, err := u.startSession(user, client)
+	if err != nil {
+		return nil, err
+	}
+	return &domain.LoginResult{TokenPair: tokens}, nil
+}
+
+// upgradePasswordHash rehashes the password the user just logged in with when
+// the stored hash uses an outdated algorithm or cost. Failing to is not fatal;
+// it is retried on the next login.
+func (u *userUsecase) upgradePasswordHash(user *domain.User, password string) {
+	if !u.passwordHasher.NeedsRehash(user.Password) {
+		return
+	}
+
+	hashedPassword, err := u.passwordHasher.Hash(password)
+	if err == nil {
+		err = u.userRepo.UpdatePassword(user.ID, hashedPassword)
+	}
+	if err != nil {
+		log.Printf("Failed to upgrade password hash of user %d: %v", user.ID, err)
+		return
+	}
+	user.Password = hashedPassword
+}
+
+// checkLoginAttempts fails while any of keys is backing off or locked out.
+func (u *userUsecase) checkLoginAttempts(keys []domain.LoginAttemptKey) error {
+	for _, key := range keys {
+		if err := u.loginAttempts.Check(key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// recordLoginFailure counts a failed password or second factor against keys.
+func (u *userUsecase) recordLoginFailure(keys []domain.LoginAttemptKey) {
+	for _, key := range keys {
+		if err := u.loginAttempts.RecordFailure(key); err != nil {
+			log.Printf("Failed to record failed login for %s: %v", key, err)
+		}
+	}
+}
+
+// LoginTwoFactor completes a login whose password was verified by Login, given a
+// TOTP or recovery code.
+func (u *userUsecase) LoginTwoFactor(challengeToken, code string, client domain.ClientInfo) (*types.TokenPair, error) {
+	claims, err := auth.ValidateChallengeToken(challengeToken)
+	if err != nil {
+		return nil, domain.ErrTwoFactorChallenge
+	}
+
+	user, err := u.userRepo.GetByID(claims.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if user.DisabledAt != nil {
+		return nil, domain.ErrUserDisabled
+	}
+
+	attemptKeys := []domain.LoginAttemptKey{domain.UsernameLoginKey(user.Username), domain.IPLoginKey(client.IPAddress)}
+	if err := u.checkLoginAttempts(attemptKeys); err != nil {
+		return nil, err
+	}
+	if err := u.twoFactor.Authenticate(user.ID, code); err != nil {
+		if errors.Is(err, domain.ErrTwoFactorCodeInvalid) {
+			u.recordLoginFailure(attemptKeys)
+		}
+		return nil, err
+	}
+	return u.startSession(user, client)
+}
+
+// startSession completes a login: it clears the user's failed attempts, records
+// a new session for the device and issues its first tokens.
+func (u *userUsecase) startSession(user *domain.User, client domain.ClientInfo) (*types.TokenPair, error) {
+	if err := u.loginAttempts.Reset(domain.UsernameLoginKey(user.Username)); err != nil {
+		log.Printf("Failed to reset failed logins of user %d: %v", user.ID, err)
+	}
+
+	familyID, err := auth.NewTokenID()
+	if err != nil {
+		return nil, err
+	}
+
+	session := &domain.Session{
+		UserID:     user.ID,
+		FamilyID:   familyID,
+		UserAgent:  client.UserAgent,
+		IPAddress:  client.IPAddress,
+		LastUsedAt: time.Now(),
+	}
+	if err := u.sessionRepo.Create(session); err != nil {
+		return nil, err
 	}
 
-	authToken, err := auth.GenerateTokenPair(user.ID)
+	tokens, err := u.issueTokens(user, familyID)
 	if err != nil {
 		log.Printf("Token generation failed: %v", err)
 		return nil, err
 	}
+	return tokens, nil
+}
 
-	return &types.TokenPair{
-		AccessToken:  authToken.AccessToken,
-		RefreshToken: authToken.RefreshToken,
+// RefreshToken rotates a refresh token: the presented one is spent and a new
+// pair of the same session is issued. Presenting a spent token again means it
+// leaked, so the whole session is revoked.
+func (u *userUsecase) RefreshToken(refreshToken string, client domain.ClientInfo) (*types.TokenPair, error) {
+	claims, stored, err := u.lookupRefreshToken(refreshToken)
+	if err != nil {
+		return nil, err
+	}
+	if stored.RevokedAt != nil {
+		return nil, domain.ErrRefreshTokenInvalid
+	}
+
+	session, err := u.sessionRepo.GetByFamilyID(stored.FamilyID)
+	if err != nil {
+		return nil, err
+	}
+	if session.RevokedAt != nil {
+		return nil, domain.ErrSessionRevoked
+	}
+
+	if err := u.refreshTokenRepo.Use(stored.JTI); err != nil {
+		if errors.Is(err, domain.ErrRefreshTokenReused) {
+			log.Printf("Refresh token reuse for user %d, revoking session %d", stored.UserID, session.ID)
+			if revokeErr := u.revokeSession(session.ID, session.UserID); revokeErr != nil {
+				return nil, revokeErr
+			}
+		}
+		return nil, err
+	}
+
+	user, err := u.userRepo.GetByID(claims.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if user.DisabledAt != nil {
+		return nil, domain.ErrUserDisabled
+	}
+	if err := u.sessionRepo.Touch(session.ID, client); err != nil {
+		return nil, err
+	}
+
+	return u.issueTokens(user, stored.FamilyID)
+}
+
+// ValidateAccessToken also rejects tokens of revoked sessions, so logging a
+// device out takes effect before its access token expires.
+func (u *userUsecase) ValidateAccessToken(token string) (*domain.User, *types.UserClaims, error) {
+	claims, err := auth.ValidateAccessToken(token)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	session, err := u.sessionRepo.GetByFamilyID(claims.SessionID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if session.RevokedAt != nil || session.UserID != claims.UserID {
+		return nil, nil, domain.ErrSessionRevoked
+	}
+
+	user, err := u.userRepo.GetByID(claims.UserID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if user.DisabledAt != nil {
+		return nil, nil, domain.ErrUserDisabled
+	}
+	return user, &types.UserClaims{
+		ID:        claims.UserID,
+		Type:      claims.Type,
+		SessionID: claims.SessionID,
+		Scopes:    claims.Scopes,
 	}, nil
 }
 
-func (u *userUsecase) RefreshToken(refreshToken string) (*types.TokenPair, error) {
+func (u *userUsecase) Logout(refreshToken string) error {
+	_, stored, err := u.lookupRefreshToken(refreshToken)
+	if err != nil {
+		return err
+	}
+
+	session, err := u.sessionRepo.GetByFamilyID(stored.FamilyID)
+	if err != nil {
+		return err
+	}
+	return u.revokeSession(session.ID, session.UserID)
+}
+
+func (u *userUsecase) LogoutAll(user *domain.User) error {
+	if err := u.sessionRepo.RevokeAllByUserID(user.ID); err != nil {
+		return err
+	}
+	return u.refreshTokenRepo.RevokeAllByUserID(user.ID)
+}
+
+func (u *userUsecase) revokeSession(id, userID uint) error {
+	return revokeSession(u.sessionRepo, u.refreshTokenRepo, id, userID)
+}
+
+// lookupRefreshToken validates the token signature and finds its server side
+// record, checking the token is the one that was issued under that jti.
+func (u *userUsecase) lookupRefreshToken(refreshToken string) (*auth.JWTClaims, *domain.RefreshToken, error) {
 	claims, err := auth.ValidateRefreshToken(refreshToken)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
+	}
+	if claims.ID == "" {
+		return nil, nil, domain.ErrRefreshTokenInvalid
 	}
 
-	user, err := u.userRepo.GetByID(claims.UserID)
+	stored, err := u.refreshTokenRepo.GetByJTI(claims.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if stored.TokenHash != auth.HashToken(refreshToken) || stored.UserID != claims.UserID {
+		return nil, nil, domain.ErrRefreshTokenInvalid
+	}
+	return claims, stored, nil
+}
+
+// issueTokens generates a token pair for the session and records its refresh token.
+func (u *userUsecase) issueTokens(user *domain.User, familyID string) (*types.TokenPair, error) {
+	authToken, err := auth.GenerateTokenPair(user.ID, familyID, userScopes(user))
 	if err != nil {
 		return nil, err
 	}
 
-	authToken, err := auth.GenerateTokenPair(user.ID)
+	err = u.refreshTokenRepo.Create(&domain.RefreshToken{
+		UserID:    user.ID,
+		JTI:       authToken.RefreshTokenID,
+		FamilyID:  familyID,
+		TokenHash: auth.HashToken(authToken.RefreshToken),
+		ExpiresAt: authToken.RefreshExpiresAt,
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -89,11 +382,11 @@ func (u *userUsecase) RefreshToken(refreshToken string) (*types.TokenPair, error
 	}, nil
 }
 
-func (u *userUsecase) ValidateAccessToken(token string) (*domain.User, error) {
-	claims, err := auth.ValidateAccessToken(token)
-	if err != nil {
-		return nil, err
+// userScopes are the scopes granted to a user's password login, and the most
+// any of the user's tokens can exercise.
+func userScopes(user *domain.User) []string {
+	if user.IsAdmin() {
+		return domain.AllScopes
 	}
-
-	return u.userRepo.GetByID(claims.UserID)
+	return domain.UserScopes
 }
@@ -0,0 +1,198 @@
+package usecase
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"notes-app/internal/domain"
+	"notes-app/internal/repository"
+	"notes-app/pkg/auth"
+)
+
+// plainHasher stores passwords as they are; hashing is not under test here.
+type plainHasher struct{}
+
+func (plainHasher) Hash(password string) (string, error)          { return password, nil }
+func (plainHasher) Verify(password, encoded string) (bool, error) { return password == encoded, nil }
+func (plainHasher) NeedsRehash(encoded string) bool               { return false }
+
+type stubUserRepository struct {
+	domain.UserRepository
+	users map[uint]*domain.User
+}
+
+func (r *stubUserRepository) GetByUsername(username string) (*domain.User, error) {
+	for _, user := range r.users {
+		if user.Username == username {
+			found := *user
+			return &found, nil
+		}
+	}
+	return nil, domain.ErrUserNotFound
+}
+
+func (r *stubUserRepository) GetByID(id uint) (*domain.User, error) {
+	user, ok := r.users[id]
+	if !ok {
+		return nil, domain.ErrUserNotFound
+	}
+	found := *user
+	return &found, nil
+}
+
+type stubSessionRepository struct {
+	domain.SessionRepository
+	sessions []*domain.Session
+}
+
+func (r *stubSessionRepository) Create(session *domain.Session) error {
+	session.ID = uint(len(r.sessions) + 1)
+	stored := *session
+	r.sessions = append(r.sessions, &stored)
+	return nil
+}
+
+func (r *stubSessionRepository) GetByFamilyID(familyID string) (*domain.Session, error) {
+	for _, session := range r.sessions {
+		if session.FamilyID == familyID {
+			found := *session
+			return &found, nil
+		}
+	}
+	return nil, domain.ErrSessionNotFound
+}
+
+func (r *stubSessionRepository) Touch(id uint, client domain.ClientInfo) error {
+	return nil
+}
+
+func (r *stubSessionRepository) Revoke(id, userID uint) (*domain.Session, error) {
+	for _, session := range r.sessions {
+		if session.ID == id && session.UserID == userID {
+			now := time.Now()
+			session.RevokedAt = &now
+			found := *session
+			return &found, nil
+		}
+	}
+	return nil, domain.ErrSessionNotFound
+}
+
+func (r *stubSessionRepository) RevokeAllByUserID(userID uint) error {
+	now := time.Now()
+	for _, session := range r.sessions {
+		if session.UserID == userID && session.RevokedAt == nil {
+			session.RevokedAt = &now
+		}
+	}
+	return nil
+}
+
+type noTwoFactor struct {
+	domain.TwoFactorUsecase
+}
+
+func (noTwoFactor) IsEnabled(userID uint) (bool, error) { return false, nil }
+
+func newTestUserUsecase(t *testing.T) (domain.UserUsecase, domain.RefreshTokenRepository) {
+	t.Helper()
+
+	keys, err := auth.NewKeyManager("test", auth.NewHMACKey("test", []byte("test-secret")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	auth.SetKeyManager(keys)
+
+	users := &stubUserRepository{users: map[uint]*domain.User{
+		1: {ID: 1, Username: "alice", Password: "correct horse", Role: domain.RoleUser},
+		2: {ID: 2, Username: "bob", Password: "battery staple", Role: domain.RoleUser},
+	}}
+	refreshTokens := repository.NewMemoryRefreshTokenRepository()
+	attempts := repository.NewMemoryLoginAttemptTracker(domain.LoginAttemptPolicy{LockoutDuration: time.Minute}, time.Now)
+
+	u := NewUserUsecase(users, refreshTokens, &stubSessionRepository{}, noTwoFactor{}, nil, attempts,
+		domain.NewPasswordPolicy(8, nil), plainHasher{}, nil)
+	return u, refreshTokens
+}
+
+func login(t *testing.T, u domain.UserUsecase, username, password string) string {
+	t.Helper()
+
+	result, err := u.Login(username, password, domain.ClientInfo{IPAddress: "192.0.2.1"})
+	if err != nil {
+		t.Fatalf("Login(%s): %v", username, err)
+	}
+	return result.TokenPair.RefreshToken
+}
+
+func TestRefreshTokenRotation(t *testing.T) {
+	u, _ := newTestUserUsecase(t)
+	first := login(t, u, "alice", "correct horse")
+
+	rotated, err := u.RefreshToken(first, domain.ClientInfo{})
+	if err != nil {
+		t.Fatalf("first refresh: %v", err)
+	}
+	if rotated.RefreshToken == first {
+		t.Fatal("refresh returned the presented token instead of a new one")
+	}
+
+	if _, err := u.RefreshToken(first, domain.ClientInfo{}); !errors.Is(err, domain.ErrRefreshTokenReused) {
+		t.Fatalf("replaying a rotated token: got %v, want ErrRefreshTokenReused", err)
+	}
+}
+
+func TestRefreshTokenReuseRevokesFamily(t *testing.T) {
+	u, refreshTokens := newTestUserUsecase(t)
+	first := login(t, u, "alice", "correct horse")
+	other := login(t, u, "alice", "correct horse")
+
+	rotated, err := u.RefreshToken(first, domain.ClientInfo{})
+	if err != nil {
+		t.Fatalf("first refresh: %v", err)
+	}
+	if _, err := u.RefreshToken(first, domain.ClientInfo{}); !errors.Is(err, domain.ErrRefreshTokenReused) {
+		t.Fatalf("replaying a rotated token: got %v, want ErrRefreshTokenReused", err)
+	}
+
+	// The legitimate holder's newer token belongs to the same family and is dead too
+	if _, err := u.RefreshToken(rotated.RefreshToken, domain.ClientInfo{}); err == nil {
+		t.Error("token rotated from a replayed one still refreshes")
+	}
+	claims, err := auth.ValidateRefreshToken(rotated.RefreshToken)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stored, err := refreshTokens.GetByJTI(claims.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.RevokedAt == nil {
+		t.Error("token rotated from a replayed one was not revoked")
+	}
+
+	// A separate login of the same user is a different family and survives
+	if _, err := u.RefreshToken(other, domain.ClientInfo{}); err != nil {
+		t.Errorf("other session: %v", err)
+	}
+}
+
+func TestLogoutAllRevokesEveryToken(t *testing.T) {
+	u, _ := newTestUserUsecase(t)
+	alice := []string{login(t, u, "alice", "correct horse"), login(t, u, "alice", "correct horse")}
+	bob := login(t, u, "bob", "battery staple")
+
+	if err := u.LogoutAll(&domain.User{ID: 1, Username: "alice"}); err != nil {
+		t.Fatalf("LogoutAll: %v", err)
+	}
+
+	for i, token := range alice {
+		if _, err := u.RefreshToken(token, domain.ClientInfo{}); err == nil {
+			t.Errorf("alice's token %d still refreshes after logout-all", i)
+		}
+	}
+	if _, err := u.RefreshToken(bob, domain.ClientInfo{}); err != nil {
+		t.Errorf("bob's token was revoked by alice's logout-all: %v", err)
+	}
+}
@@ -1,74 +1,177 @@
 package auth
 
 import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"github.com/golang-jwt/jwt/v5"
 	"os"
 	"time"
 )
 
+const (
+	RefreshTokenTTL = 7 * 24 * time.Hour
+
+	// ChallengeTokenTTL bounds the time between the password and the second
+	// factor of a two-factor login.
+	ChallengeTokenTTL = 5 * time.Minute
+)
+
+var ErrKeysNotConfigured = errors.New("signing keys not configured")
+
+// keys signs and verifies every token; it is set once at startup by SetKeyManager.
+var keys *KeyManager
+
+func SetKeyManager(m *KeyManager) {
+	keys = m
+}
+
+// KeyManagerFromEnv loads the keys in JWT_KEYS_DIR, signing with JWT_ACTIVE_KID
+// (see LoadKeyManager). Without a key directory JWT_SECRET becomes the only,
+// HS256, key. Tokens issued before kid headers were introduced are rejected:
+// they carry no session or jti, which access and refresh tokens now need, so
+// their users have to log in again.
+func KeyManagerFromEnv() (*KeyManager, error) {
+	var m *KeyManager
+	var err error
+	if dir := os.Getenv("JWT_KEYS_DIR"); dir != "" {
+		m, err = LoadKeyManager(dir, os.Getenv("JWT_ACTIVE_KID"))
+	} else if secret := os.Getenv("JWT_SECRET"); secret != "" {
+		m, err = NewKeyManager("default", NewHMACKey("default", []byte(secret)))
+	} else {
+		err = ErrKeysNotConfigured
+	}
+	if err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// TokenPair carries, besides the tokens themselves, the identity of the refresh
+// token so the caller can persist it.
 type TokenPair struct {
-	AccessToken  string `json:"access_token"`
-	RefreshToken string `json:"refresh_token"`
+	AccessToken      string    `json:"access_token"`
+	RefreshToken     string    `json:"refresh_token"`
+	RefreshTokenID   string    `json:"-"`
+	RefreshExpiresAt time.Time `json:"-"`
 }
 
+// JWTClaims identifies refresh tokens by their jti (RegisteredClaims.ID). Both
+// tokens carry the session they belong to as sid, which is also the family of
+// every refresh token rotated from the same login. Scopes are only set on access
+// tokens; a refresh recomputes them.
 type JWTClaims struct {
-	UserID uint   `json:"user_id"`
-	Type   string `json:"token_type"` // "access" or "refresh"
+	UserID    uint     `json:"user_id"`
+	Type      string   `json:"token_type"` // "access" or "refresh"
+	SessionID string   `json:"sid,omitempty"`
+	Scopes    []string `json:"scopes,omitempty"`
 	jwt.RegisteredClaims
 }
 
-func GenerateTokenPair(userID uint) (*TokenPair, error) {
+func GenerateTokenPair(userID uint, sessionID string, scopes []string) (*TokenPair, error) {
+	if keys == nil {
+		return nil, ErrKeysNotConfigured
+	}
+
 	// Generate Access Token (short-lived)
 	accessClaims := JWTClaims{
-		UserID: userID,
-		Type:   "access",
+		UserID:    userID,
+		Type:      "access",
+		SessionID: sessionID,
+		Scopes:    scopes,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(15 * time.Minute)), // 15 minutes
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 		},
 	}
 
-	accessToken := jwt.NewWithClaims(jwt.SigningMethodHS256, accessClaims)
-	accessTokenString, err := accessToken.SignedString([]byte(os.Getenv("JWT_SECRET")))
+	accessTokenString, err := keys.Sign(accessClaims)
 	if err != nil {
 		return nil, err
 	}
 
 	// Generate Refresh Token (long-lived)
+	refreshID, err := NewTokenID()
+	if err != nil {
+		return nil, err
+	}
+	refreshExpiresAt := time.Now().Add(RefreshTokenTTL)
 	refreshClaims := JWTClaims{
-		UserID: userID,
-		Type:   "refresh",
+		UserID:    userID,
+		Type:      "refresh",
+		SessionID: sessionID,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(7 * 24 * time.Hour)), // 7 days
+			ID:        refreshID,
+			ExpiresAt: jwt.NewNumericDate(refreshExpiresAt),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 		},
 	}
 
-	refreshToken := jwt.NewWithClaims(jwt.SigningMethodHS256, refreshClaims)
-	refreshTokenString, err := refreshToken.SignedString([]byte(os.Getenv("JWT_REFRESH_SECRET")))
+	refreshTokenString, err := keys.Sign(refreshClaims)
 	if err != nil {
 		return nil, err
 	}
 
 	return &TokenPair{
-		AccessToken:  accessTokenString,
-		RefreshToken: refreshTokenString,
+		AccessToken:      accessTokenString,
+		RefreshToken:     refreshTokenString,
+		RefreshTokenID:   refreshID,
+		RefreshExpiresAt: refreshExpiresAt,
 	}, nil
 }
 
+// GenerateChallengeToken issues the token that carries a password-verified login
+// over to its two-factor step. It grants no access by itself.
+func GenerateChallengeToken(userID uint) (string, error) {
+	if keys == nil {
+		return "", ErrKeysNotConfigured
+	}
+
+	return keys.Sign(JWTClaims{
+		UserID: userID,
+		Type:   "2fa_challenge",
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(ChallengeTokenTTL)),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+		},
+	})
+}
+
+// NewTokenID returns a random identifier suitable for a jti or a token family.
+func NewTokenID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// HashToken is how tokens are stored server side; only the hash is persisted so
+// a database leak does not hand out usable tokens.
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
 func ValidateAccessToken(tokenString string) (*JWTClaims, error) {
-	return validateToken(tokenString, "access", os.Getenv("JWT_SECRET"))
+	return validateToken(tokenString, "access")
 }
 
 func ValidateRefreshToken(tokenString string) (*JWTClaims, error) {
-	return validateToken(tokenString, "refresh", os.Getenv("JWT_REFRESH_SECRET"))
+	return validateToken(tokenString, "refresh")
 }
 
-func validateToken(tokenString, tokenType, secret string) (*JWTClaims, error) {
-	token, err := jwt.ParseWithClaims(tokenString, &JWTClaims{}, func(token *jwt.Token) (interface{}, error) {
-		return []byte(secret), nil
-	})
+func ValidateChallengeToken(tokenString string) (*JWTClaims, error) {
+	return validateToken(tokenString, "2fa_challenge")
+}
+
+func validateToken(tokenString, tokenType string) (*JWTClaims, error) {
+	if keys == nil {
+		return nil, ErrKeysNotConfigured
+	}
+
+	token, err := keys.Parse(tokenString, &JWTClaims{})
 
 	if err != nil {
 		return nil, err
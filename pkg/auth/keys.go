@@ -0,0 +1,227 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var (
+	ErrUnknownKey      = errors.New("unknown signing key")
+	ErrUnsupportedKey  = errors.New("unsupported key type, expected an RSA or Ed25519 private key")
+	ErrNoSigningKeys   = errors.New("no signing keys configured")
+	ErrAlgorithmDenied = errors.New("token algorithm does not match its key")
+)
+
+// SigningKey is one key of a KeyManager, identified by the kid header of the
+// tokens it signs.
+type SigningKey struct {
+	ID        string
+	Method    jwt.SigningMethod
+	signKey   interface{}
+	verifyKey interface{}
+}
+
+func NewHMACKey(id string, secret []byte) *SigningKey {
+	return &SigningKey{ID: id, Method: jwt.SigningMethodHS256, signKey: secret, verifyKey: secret}
+}
+
+func NewRSAKey(id string, key *rsa.PrivateKey) *SigningKey {
+	return &SigningKey{ID: id, Method: jwt.SigningMethodRS256, signKey: key, verifyKey: &key.PublicKey}
+}
+
+func NewEd25519Key(id string, key ed25519.PrivateKey) *SigningKey {
+	return &SigningKey{ID: id, Method: jwt.SigningMethodEdDSA, signKey: key, verifyKey: key.Public()}
+}
+
+// ParsePrivateKeyPEM reads a PKCS#8 (or PKCS#1 RSA) private key, choosing RS256
+// or EdDSA from the key type.
+func ParsePrivateKeyPEM(id string, data []byte) (*SigningKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("key %s: no PEM block found", id)
+	}
+
+	var parsed crypto.PrivateKey
+	var err error
+	if block.Type == "RSA PRIVATE KEY" {
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	} else {
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("key %s: %w", id, err)
+	}
+
+	switch key := parsed.(type) {
+	case *rsa.PrivateKey:
+		return NewRSAKey(id, key), nil
+	case ed25519.PrivateKey:
+		return NewEd25519Key(id, key), nil
+	default:
+		return nil, fmt.Errorf("key %s: %w", id, ErrUnsupportedKey)
+	}
+}
+
+// KeyManager signs tokens with its active key and verifies them with whichever
+// key their kid names, so keys can be rotated without invalidating tokens that
+// are still in flight.
+type KeyManager struct {
+	keys   map[string]*SigningKey
+	active *SigningKey
+}
+
+func NewKeyManager(activeID string, keys ...*SigningKey) (*KeyManager, error) {
+	if len(keys) == 0 {
+		return nil, ErrNoSigningKeys
+	}
+
+	m := &KeyManager{
+		keys: make(map[string]*SigningKey, len(keys)),
+	}
+	for _, key := range keys {
+		if _, exists := m.keys[key.ID]; exists {
+			return nil, fmt.Errorf("duplicate key id %q", key.ID)
+		}
+		m.keys[key.ID] = key
+	}
+
+	active, ok := m.keys[activeID]
+	if !ok {
+		return nil, fmt.Errorf("active key %q: %w", activeID, ErrUnknownKey)
+	}
+	m.active = active
+	return m, nil
+}
+
+// LoadKeyManager reads every key in dir, using the file name without extension
+// as the kid: *.pem files hold RSA or Ed25519 private keys and *.secret files
+// hold raw HS256 secrets. activeID defaults to the last kid in sort order, so
+// keys named by date rotate by adding a newer file.
+func LoadKeyManager(dir, activeID string) (*KeyManager, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var keys []*SigningKey
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		ext := filepath.Ext(entry.Name())
+		id := strings.TrimSuffix(entry.Name(), ext)
+		if ext != ".pem" && ext != ".secret" {
+			continue
+		}
+
+		data, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		if ext == ".secret" {
+			keys = append(keys, NewHMACKey(id, []byte(strings.TrimSpace(string(data)))))
+			continue
+		}
+		key, err := ParsePrivateKeyPEM(id, data)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	if len(keys) == 0 {
+		return nil, ErrNoSigningKeys
+	}
+
+	if activeID == "" {
+		sort.Slice(keys, func(i, j int) bool { return keys[i].ID < keys[j].ID })
+		activeID = keys[len(keys)-1].ID
+	}
+	return NewKeyManager(activeID, keys...)
+}
+
+// ActiveKeyID is the kid new tokens are signed with.
+func (m *KeyManager) ActiveKeyID() string {
+	return m.active.ID
+}
+
+func (m *KeyManager) Sign(claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(m.active.Method, claims)
+	token.Header["kid"] = m.active.ID
+	return token.SignedString(m.active.signKey)
+}
+
+// Parse verifies tokenString into claims. The algorithm must be the one of the
+// key the kid names, which rules out algorithm confusion between HS256 secrets
+// and public keys.
+func (m *KeyManager) Parse(tokenString string, claims jwt.Claims) (*jwt.Token, error) {
+	return jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		key, ok := m.keys[kid]
+		if !ok {
+			return nil, ErrUnknownKey
+		}
+		if token.Method.Alg() != key.Method.Alg() {
+			return nil, ErrAlgorithmDenied
+		}
+		return key.verifyKey, nil
+	})
+}
+
+// JWK is a public key in JSON Web Key form (RFC 7517).
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWKS lists the public halves of the asymmetric keys. HS256 secrets are never
+// published, so tokens signed with them can only be verified by this service.
+func (m *KeyManager) JWKS() JWKSet {
+	set := JWKSet{Keys: []JWK{}}
+	for _, key := range m.keys {
+		switch pub := key.verifyKey.(type) {
+		case *rsa.PublicKey:
+			set.Keys = append(set.Keys, JWK{
+				Kty: "RSA",
+				Kid: key.ID,
+				Use: "sig",
+				Alg: key.Method.Alg(),
+				N:   base64.RawURLEncoding.EncodeToString(pub.N.Bytes()),
+				E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes()),
+			})
+		case ed25519.PublicKey:
+			set.Keys = append(set.Keys, JWK{
+				Kty: "OKP",
+				Kid: key.ID,
+				Use: "sig",
+				Alg: key.Method.Alg(),
+				Crv: "Ed25519",
+				X:   base64.RawURLEncoding.EncodeToString(pub),
+			})
+		}
+	}
+	sort.Slice(set.Keys, func(i, j int) bool { return set.Keys[i].Kid < set.Keys[j].Kid })
+	return set
+}
@@ -0,0 +1,74 @@
+package config
+
+import (
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// String reads a variable from the environment, falling back when it is unset.
+func String(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
+
+// Duration reads a Go duration string (e.g. "720h") from the environment,
+// falling back when the variable is unset or malformed.
+func Duration(key string, fallback time.Duration) time.Duration {
+	raw := os.Getenv(key)
+	if raw == "" {
+		return fallback
+	}
+	value, err := time.ParseDuration(raw)
+	if err != nil {
+		log.Printf("Invalid duration for %s (%q), using %s", key, raw, fallback)
+		return fallback
+	}
+	return value
+}
+
+// Int reads an integer from the environment, falling back when the variable is unset or malformed.
+func Int(key string, fallback int) int {
+	raw := os.Getenv(key)
+	if raw == "" {
+		return fallback
+	}
+	value, err := strconv.Atoi(raw)
+	if err != nil {
+		log.Printf("Invalid integer for %s (%q), using %d", key, raw, fallback)
+		return fallback
+	}
+	return value
+}
+
+// List reads a comma separated list from the environment, dropping empty entries.
+func List(key string) []string {
+	var values []string
+	for _, value := range strings.Split(os.Getenv(key), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+// ListFile reads a file with one value per line, skipping blank lines and lines
+// starting with #.
+func ListFile(path string) ([]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var values []string
+	for _, line := range strings.Split(string(data), "\n") {
+		if line = strings.TrimSpace(line); line != "" && !strings.HasPrefix(line, "#") {
+			values = append(values, line)
+		}
+	}
+	return values, nil
+}
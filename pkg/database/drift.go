@@ -0,0 +1,450 @@
+package database
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"notes-app/internal/domain"
+
+	"gorm.io/gorm/schema"
+)
+
+// Kinds of SchemaDrift.
+const (
+	DriftMissingTable      = "missing_table"
+	DriftExtraTable        = "extra_table"
+	DriftMissingColumn     = "missing_column"
+	DriftExtraColumn       = "extra_column"
+	DriftColumnType        = "column_type"
+	DriftNullability       = "nullability"
+	DriftDefault           = "default"
+	DriftMissingIndex      = "missing_index"
+	DriftExtraIndex        = "extra_index"
+	DriftIndexDefinition   = "index_definition"
+	DriftMissingConstraint = "missing_constraint"
+	DriftExtraConstraint   = "extra_constraint"
+)
+
+// driftModels are the models whose tables the migrations create.
+var driftModels = []interface{}{
+	&domain.User{},
+	&domain.Note{},
+	&domain.Tag{},
+	&domain.Notebook{},
+	&domain.NoteRevision{},
+	&domain.SavedSearch{},
+	&domain.RefreshToken{},
+	&domain.Session{},
+	&domain.PersonalAccessToken{},
+	&domain.TwoFactor{},
+	&domain.RecoveryCode{},
+	&domain.LoginAttempt{},
+	&domain.PasswordResetToken{},
+	&domain.EmailVerificationToken{},
+	&MigrationHistory{},
+	&SchemaMigration{},
+}
+
+// migrationOnly lists the columns and indexes, as table.name, that migrations
+// create but no model describes.
+var migrationOnly = map[string]bool{
+	"notes.search_vector":           true,
+	"notes.idx_notes_search_vector": true,
+}
+
+// SchemaDrift is one difference between the models and the live schema. Name
+// is the column, index or constraint it concerns; Expected comes from the
+// models and Actual from the database.
+type SchemaDrift struct {
+	Table    string `json:"table"`
+	Kind     string `json:"kind"`
+	Name     string `json:"name,omitempty"`
+	Expected string `json:"expected,omitempty"`
+	Actual   string `json:"actual,omitempty"`
+}
+
+func (d SchemaDrift) String() string {
+	name := d.Table
+	if d.Name != "" {
+		name += "." + d.Name
+	}
+	return fmt.Sprintf("%s: %s (expected %q, actual %q)", name, d.Kind, d.Expected, d.Actual)
+}
+
+// tableSchema is a table as the drift detector compares it. Indexes map names
+// to definitions; constraints map definitions to names, since the name
+// Postgres picks for an inline constraint differs from GORM's.
+type tableSchema struct {
+	columns     map[string]columnSchema
+	indexes     map[string]string
+	constraints map[string]string
+}
+
+type columnSchema struct {
+	Type    string
+	NotNull bool
+	Default string
+}
+
+func newTableSchema() *tableSchema {
+	return &tableSchema{
+		columns:     make(map[string]columnSchema),
+		indexes:     make(map[string]string),
+		constraints: make(map[string]string),
+	}
+}
+
+// DetectDrift compares the tables, columns, indexes and constraints the models
+// describe with the ones in the database, using GORM's schema parser for the
+// former and pg_catalog for the latter.
+func (s *MigrationService) DetectDrift() ([]SchemaDrift, error) {
+	expected, err := s.modelSchema()
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse models: %v", err)
+	}
+	actual, err := s.liveSchema()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read database schema: %v", err)
+	}
+	return diffSchemas(expected, actual), nil
+}
+
+// modelSchema is the schema GORM would create for driftModels, including the
+// join tables of many2many relations.
+func (s *MigrationService) modelSchema() (map[string]*tableSchema, error) {
+	tables := make(map[string]*tableSchema)
+	var add func(sch *schema.Schema)
+	add = func(sch *schema.Schema) {
+		if _, ok := tables[sch.Table]; ok {
+			return
+		}
+		table := newTableSchema()
+		tables[sch.Table] = table
+
+		for _, dbName := range sch.DBNames {
+			field := sch.FieldsByDBName[dbName]
+			if field.IgnoreMigration {
+				continue
+			}
+			dataType := s.db.Dialector.DataTypeOf(field)
+			column := columnSchema{Type: normalizeType(dataType), NotNull: field.NotNull || field.PrimaryKey}
+			if strings.HasSuffix(dataType, "serial") {
+				column.Default = "nextval"
+			} else if field.HasDefaultValue && field.DefaultValue != "" {
+				column.Default = normalizeDefault(field.DefaultValue)
+			}
+			table.columns[dbName] = column
+
+			if field.Unique {
+				table.constraints[fmt.Sprintf("UNIQUE (%s)", dbName)] = s.db.NamingStrategy.UniqueName(sch.Table, dbName)
+			}
+		}
+		if len(sch.PrimaryFieldDBNames) > 0 {
+			table.constraints[fmt.Sprintf("PRIMARY KEY (%s)", strings.Join(sch.PrimaryFieldDBNames, ", "))] = sch.Table + "_pkey"
+		}
+
+		for _, index := range sch.ParseIndexes() {
+			var columns []string
+			for _, option := range index.Fields {
+				if option.Field != nil {
+					columns = append(columns, option.DBName)
+				} else {
+					columns = append(columns, option.Expression)
+				}
+			}
+			table.indexes[index.Name] = indexDefinition(index.Class == "UNIQUE", columns)
+		}
+
+		for _, rel := range sch.Relationships.Relations {
+			if rel.Field.IgnoreMigration {
+				continue
+			}
+			// GORM creates a foreign key with the table that holds it.
+			if constraint := rel.ParseConstraint(); constraint != nil && constraint.Schema == sch {
+				table.constraints[foreignKeyDefinition(constraint)] = constraint.Name
+			}
+			if rel.JoinTable != nil {
+				add(rel.JoinTable)
+			}
+		}
+	}
+
+	cache := &sync.Map{}
+	for _, model := range driftModels {
+		sch, err := schema.Parse(model, cache, s.db.NamingStrategy)
+		if err != nil {
+			return nil, err
+		}
+		add(sch)
+	}
+	return tables, nil
+}
+
+// liveSchema reads the tables of the current schema from the database.
+func (s *MigrationService) liveSchema() (map[string]*tableSchema, error) {
+	var tableNames []string
+	err := s.db.Raw(`
+		SELECT table_name FROM information_schema.tables
+		WHERE table_schema = current_schema() AND table_type = 'BASE TABLE'
+	`).Scan(&tableNames).Error
+	if err != nil {
+		return nil, err
+	}
+	tables := make(map[string]*tableSchema, len(tableNames))
+	for _, name := range tableNames {
+		tables[name] = newTableSchema()
+	}
+
+	// format_type gives the declared type with its length, e.g.
+	// "character varying(20)", which information_schema splits up.
+	var columns []struct {
+		TableName     string
+		ColumnName    string
+		DataType      string
+		IsNullable    string
+		ColumnDefault *string
+	}
+	err = s.db.Raw(`
+		SELECT c.table_name, c.column_name, format_type(a.atttypid, a.atttypmod) AS data_type,
+			c.is_nullable, c.column_default
+		FROM information_schema.columns c
+		JOIN pg_catalog.pg_attribute a
+			ON a.attrelid = format('%I.%I', c.table_schema, c.table_name)::regclass AND a.attname = c.column_name
+		WHERE c.table_schema = current_schema()
+	`).Scan(&columns).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range columns {
+		table, ok := tables[c.TableName]
+		if !ok {
+			continue
+		}
+		column := columnSchema{Type: c.DataType, NotNull: c.IsNullable == "NO"}
+		if c.ColumnDefault != nil {
+			column.Default = normalizeDefault(*c.ColumnDefault)
+		}
+		table.columns[c.ColumnName] = column
+	}
+
+	// Indexes that back a primary key or unique constraint are compared as
+	// constraints.
+	var indexes []struct {
+		TableName string
+		IndexName string
+		IsUnique  bool
+		Columns   string
+	}
+	err = s.db.Raw(`
+		SELECT t.relname AS table_name, i.relname AS index_name, ix.indisunique AS is_unique,
+			array_to_string(ARRAY(
+				SELECT pg_get_indexdef(ix.indexrelid, k.ord, true)
+				FROM generate_series(1, ix.indnkeyatts) AS k(ord)
+				ORDER BY k.ord
+			), ',') AS columns
+		FROM pg_catalog.pg_index ix
+		JOIN pg_catalog.pg_class t ON t.oid = ix.indrelid
+		JOIN pg_catalog.pg_class i ON i.oid = ix.indexrelid
+		JOIN pg_catalog.pg_namespace n ON n.oid = t.relnamespace
+		WHERE n.nspname = current_schema()
+			AND NOT EXISTS (
+				SELECT 1 FROM pg_catalog.pg_constraint con
+				WHERE con.conindid = ix.indexrelid AND con.conrelid = t.oid AND con.contype IN ('p', 'u', 'x')
+			)
+	`).Scan(&indexes).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, index := range indexes {
+		if table, ok := tables[index.TableName]; ok {
+			table.indexes[index.IndexName] = indexDefinition(index.IsUnique, strings.Split(index.Columns, ","))
+		}
+	}
+
+	var constraints []struct {
+		TableName  string
+		Name       string
+		Definition string
+	}
+	err = s.db.Raw(`
+		SELECT t.relname AS table_name, con.conname AS name, pg_get_constraintdef(con.oid) AS definition
+		FROM pg_catalog.pg_constraint con
+		JOIN pg_catalog.pg_class t ON t.oid = con.conrelid
+		JOIN pg_catalog.pg_namespace n ON n.oid = t.relnamespace
+		WHERE n.nspname = current_schema() AND con.contype IN ('p', 'u', 'f')
+	`).Scan(&constraints).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, constraint := range constraints {
+		if table, ok := tables[constraint.TableName]; ok {
+			table.constraints[constraint.Definition] = constraint.Name
+		}
+	}
+	return tables, nil
+}
+
+// diffSchemas lists the differences sorted by table, kind and name.
+func diffSchemas(expected, actual map[string]*tableSchema) []SchemaDrift {
+	drift := make([]SchemaDrift, 0)
+	for name, want := range expected {
+		got, ok := actual[name]
+		if !ok {
+			drift = append(drift, SchemaDrift{Table: name, Kind: DriftMissingTable})
+			continue
+		}
+
+		for column, wantColumn := range want.columns {
+			gotColumn, ok := got.columns[column]
+			switch {
+			case !ok:
+				drift = append(drift, SchemaDrift{Table: name, Kind: DriftMissingColumn, Name: column, Expected: wantColumn.Type})
+				continue
+			case gotColumn.Type != wantColumn.Type:
+				drift = append(drift, SchemaDrift{Table: name, Kind: DriftColumnType, Name: column, Expected: wantColumn.Type, Actual: gotColumn.Type})
+			}
+			if gotColumn.NotNull != wantColumn.NotNull {
+				drift = append(drift, SchemaDrift{Table: name, Kind: DriftNullability, Name: column, Expected: nullability(wantColumn.NotNull), Actual: nullability(gotColumn.NotNull)})
+			}
+			if gotColumn.Default != wantColumn.Default {
+				drift = append(drift, SchemaDrift{Table: name, Kind: DriftDefault, Name: column, Expected: wantColumn.Default, Actual: gotColumn.Default})
+			}
+		}
+		for column, gotColumn := range got.columns {
+			if _, ok := want.columns[column]; !ok && !migrationOnly[name+"."+column] {
+				drift = append(drift, SchemaDrift{Table: name, Kind: DriftExtraColumn, Name: column, Actual: gotColumn.Type})
+			}
+		}
+
+		for index, wantDefinition := range want.indexes {
+			gotDefinition, ok := got.indexes[index]
+			if !ok {
+				drift = append(drift, SchemaDrift{Table: name, Kind: DriftMissingIndex, Name: index, Expected: wantDefinition})
+			} else if gotDefinition != wantDefinition {
+				drift = append(drift, SchemaDrift{Table: name, Kind: DriftIndexDefinition, Name: index, Expected: wantDefinition, Actual: gotDefinition})
+			}
+		}
+		for index, gotDefinition := range got.indexes {
+			if _, ok := want.indexes[index]; !ok && !migrationOnly[name+"."+index] {
+				drift = append(drift, SchemaDrift{Table: name, Kind: DriftExtraIndex, Name: index, Actual: gotDefinition})
+			}
+		}
+
+		for definition, constraint := range want.constraints {
+			if _, ok := got.constraints[definition]; !ok {
+				drift = append(drift, SchemaDrift{Table: name, Kind: DriftMissingConstraint, Name: constraint, Expected: definition})
+			}
+		}
+		for definition, constraint := range got.constraints {
+			if _, ok := want.constraints[definition]; !ok {
+				drift = append(drift, SchemaDrift{Table: name, Kind: DriftExtraConstraint, Name: constraint, Actual: definition})
+			}
+		}
+	}
+	for name := range actual {
+		if _, ok := expected[name]; !ok {
+			drift = append(drift, SchemaDrift{Table: name, Kind: DriftExtraTable})
+		}
+	}
+
+	sort.Slice(drift, func(i, j int) bool {
+		a, b := drift[i], drift[j]
+		if a.Table != b.Table {
+			return a.Table < b.Table
+		}
+		if a.Kind != b.Kind {
+			return a.Kind < b.Kind
+		}
+		return a.Name < b.Name
+	})
+	return drift
+}
+
+// typeNames maps the type names GORM emits to the ones format_type reports.
+var typeNames = map[string]string{
+	"bigserial":   "bigint",
+	"serial":      "integer",
+	"smallserial": "smallint",
+	"int":         "integer",
+	"int2":        "smallint",
+	"int4":        "integer",
+	"int8":        "bigint",
+	"bool":        "boolean",
+	"decimal":     "numeric",
+	"float4":      "real",
+	"float8":      "double precision",
+	"varchar":     "character varying",
+	"char":        "character",
+	"timestamp":   "timestamp without time zone",
+	"timestamptz": "timestamp with time zone",
+}
+
+// normalizeType turns a GORM column type into the form format_type reports,
+// e.g. "varchar(20)" into "character varying(20)" and "timestamptz(3)" into
+// "timestamp(3) with time zone".
+func normalizeType(dataType string) string {
+	name, modifier := strings.ToLower(strings.TrimSpace(dataType)), ""
+	if i := strings.Index(name, "("); i >= 0 {
+		name, modifier = name[:i], name[i:]
+	}
+	if canonical, ok := typeNames[name]; ok {
+		name = canonical
+	}
+	if strings.HasPrefix(name, "timestamp ") && modifier != "" {
+		return "timestamp" + modifier + strings.TrimPrefix(name, "timestamp")
+	}
+	return name + modifier
+}
+
+var defaultCast = regexp.MustCompile(`::[a-z ]+(\(\d+(,\d+)?\))?(\[\])?$`)
+
+// normalizeDefault reduces a column default to its value, so GORM's "user" and
+// Postgres' "'user'::character varying" compare equal. Sequence defaults all
+// become "nextval".
+func normalizeDefault(value string) string {
+	value = strings.TrimSpace(value)
+	if strings.HasPrefix(value, "nextval(") {
+		return "nextval"
+	}
+	value = defaultCast.ReplaceAllString(value, "")
+	if len(value) >= 2 && value[0] == '\'' && value[len(value)-1] == '\'' {
+		value = strings.ReplaceAll(value[1:len(value)-1], "''", "'")
+	}
+	return value
+}
+
+func indexDefinition(unique bool, columns []string) string {
+	definition := "(" + strings.Join(columns, ", ") + ")"
+	if unique {
+		return "UNIQUE " + definition
+	}
+	return definition
+}
+
+// foreignKeyDefinition renders constraint the way pg_get_constraintdef does.
+func foreignKeyDefinition(constraint *schema.Constraint) string {
+	var columns, references []string
+	for i, field := range constraint.ForeignKeys {
+		columns = append(columns, field.DBName)
+		references = append(references, constraint.References[i].DBName)
+	}
+	definition := fmt.Sprintf("FOREIGN KEY (%s) REFERENCES %s(%s)",
+		strings.Join(columns, ", "), constraint.ReferenceSchema.Table, strings.Join(references, ", "))
+	if constraint.OnUpdate != "" {
+		definition += " ON UPDATE " + strings.ToUpper(constraint.OnUpdate)
+	}
+	if constraint.OnDelete != "" {
+		definition += " ON DELETE " + strings.ToUpper(constraint.OnDelete)
+	}
+	return definition
+}
+
+func nullability(notNull bool) string {
+	if notNull {
+		return "NOT NULL"
+	}
+	return "NULL"
+}
@@ -0,0 +1,49 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+
+	"gorm.io/gorm"
+)
+
+// migrationLockKey identifies the Postgres advisory lock taken while migrating.
+// Any constant works as long as every instance of the app uses the same one.
+const migrationLockKey int64 = 0x6e6f746573 // "notes"
+
+var ErrMigrationLockTimeout = errors.New("timed out waiting for the migration lock")
+
+// WithLock runs fn holding the migration advisory lock, so that of several
+// instances starting at once only one migrates while the others wait. The lock
+// belongs to the database session, so it is released even if the process dies.
+// Waiting longer than the service's lock timeout fails with
+// ErrMigrationLockTimeout.
+func (s *MigrationService) WithLock(fn func() error) error {
+	return s.db.Connection(func(conn *gorm.DB) error {
+		var acquired bool
+		if err := conn.Raw("SELECT pg_try_advisory_lock(?)", migrationLockKey).Scan(&acquired).Error; err != nil {
+			return err
+		}
+
+		if !acquired {
+			log.Printf("Waiting up to %s for another instance to finish migrating", s.lockTimeout)
+			ctx, cancel := context.WithTimeout(context.Background(), s.lockTimeout)
+			defer cancel()
+			if err := conn.WithContext(ctx).Exec("SELECT pg_advisory_lock(?)", migrationLockKey).Error; err != nil {
+				if ctx.Err() != nil {
+					return fmt.Errorf("%w after %s", ErrMigrationLockTimeout, s.lockTimeout)
+				}
+				return err
+			}
+		}
+
+		defer func() {
+			if err := conn.Exec("SELECT pg_advisory_unlock(?)", migrationLockKey).Error; err != nil {
+				log.Printf("Warning: Failed to release the migration lock: %v", err)
+			}
+		}()
+		return fn()
+	})
+}
@@ -3,13 +3,22 @@ package database
 import (
 	"encoding/json"
 	"fmt"
+	"strings"
 	"time"
 
+	"notes-app/internal/domain"
+	"notes-app/pkg/pagination"
+
 	"gorm.io/gorm"
 )
 
+// MigrationService applies the versioned SQL migrations and keeps the history
+// of schema changes. MigrationHistory rows written before the migration engine
+// existed are kept as legacy history next to the ones it records.
 type MigrationService struct {
-	db *gorm.DB
+	db          *gorm.DB
+	migrations  []Migration
+	lockTimeout time.Duration
 }
 
 type SchemaChange struct {
@@ -21,91 +30,98 @@ type SchemaChange struct {
 	DefaultValue string `json:"default_value,omitempty"`
 }
 
+// historyCursorKey names the only ordering of the migration history.
+const historyCursorKey = "executed_at:desc"
+
 // MigrationResponse is used for JSON response
 type MigrationResponse struct {
-	ID            uint           `json:"id"`
-	TableName     string         `json:"table_name"`
-	Operation     string         `json:"operation"`
-	Description   string         `json:"description"`
-	SchemaChanges []SchemaChange `json:"schema_changes"` // Changed from string to []SchemaChange
-	ExecutedAt    time.Time      `json:"executed_at"`
-	Version       string         `json:"version"`
-	Status        string         `json:"status"`
-	ErrorMessage  string         `json:"error_message"`
+	ID              uint           `json:"id"`
+	TableName       string         `json:"table_name"`
+	Operation       string         `json:"operation"`
+	Description     string         `json:"description"`
+	SchemaChanges   []SchemaChange `json:"schema_changes"` // Changed from string to []SchemaChange
+	ExecutedAt      time.Time      `json:"executed_at"`
+	Version         string         `json:"version"`
+	Status          string         `json:"status"`
+	ErrorMessage    string         `json:"error_message"`
+	FailedStatement string         `json:"failed_statement,omitempty"`
+	DurationMs      int64          `json:"duration_ms"`
 }
 
-func NewMigrationService(db *gorm.DB) (*MigrationService, error) {
-	if err := db.AutoMigrate(&MigrationHistory{}); err != nil {
-		return nil, fmt.Errorf("failed to create migration history table: %v", err)
-	}
-	return &MigrationService{db: db}, nil
-}
-
-func (s *MigrationService) TrackMigration(tableName, operation, description string, changes []SchemaChange) error {
-	// Check if similar migration exists
-	var existing MigrationHistory
-	err := s.db.Where("table_name = ? AND operation = ? AND description = ?",
-		tableName, operation, description).First(&existing).Error
-
-	if err == nil {
-		// Migration already exists, skip
-		return nil
+// NewMigrationService waits at most lockTimeout for another instance that is
+// migrating the same database.
+func NewMigrationService(db *gorm.DB, lockTimeout time.Duration) (*MigrationService, error) {
+	migrations, err := embeddedMigrations()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load migrations: %v", err)
 	}
 
-	// Convert changes to JSON string
-	changesJSON, err := json.Marshal(changes)
+	s := &MigrationService{db: db, migrations: migrations, lockTimeout: lockTimeout}
+	// Concurrent CREATE TABLE IF NOT EXISTS can still collide, hence the lock.
+	err = s.WithLock(func() error {
+		return db.Exec(`
+			CREATE TABLE IF NOT EXISTS schema_migrations (
+				version bigint PRIMARY KEY,
+				name text NOT NULL,
+				checksum varchar(64) NOT NULL,
+				applied_at timestamptz
+			)`).Error
+	})
 	if err != nil {
-		return fmt.Errorf("failed to marshal schema changes: %v", err)
+		return nil, fmt.Errorf("failed to create schema_migrations table: %v", err)
 	}
+	return s, nil
+}
 
-	migration := MigrationHistory{
-		TableName:     tableName,
-		Operation:     operation,
-		Description:   description,
-		SchemaChanges: string(changesJSON),
-		ExecutedAt:    time.Now(),
-		Version:       time.Now().Format("20060102150405"),
-		Status:        "SUCCESS",
+// GetMigrationHistory pages through the history, newest first. A non-empty
+// status, e.g. "FAILED", keeps only the rows with that status.
+func (s *MigrationService) GetMigrationHistory(status string, page domain.PageRequest) (domain.Page[MigrationResponse], error) {
+	page = page.Normalized()
+	query := s.db.Model(&MigrationHistory{})
+	if status != "" {
+		status = strings.ToUpper(status)
+		if status != MigrationSucceeded && status != MigrationFailed {
+			return domain.Page[MigrationResponse]{}, fmt.Errorf("%w %q", ErrUnknownStatus, status)
+		}
+		query = query.Where("status = ?", status)
 	}
 
-	tx := s.db.Begin()
-	if err := tx.Create(&migration).Error; err != nil {
-		tx.Rollback()
-		return fmt.Errorf("failed to create migration history: %v", err)
+	cursor, err := pagination.Decode(page.Cursor)
+	if err != nil {
+		return domain.Page[MigrationResponse]{}, domain.ErrInvalidCursor
+	}
+	if cursor != nil {
+		executedAt, err := time.Parse(time.RFC3339Nano, cursor.Value)
+		if cursor.Key != historyCursorKey || err != nil {
+			return domain.Page[MigrationResponse]{}, domain.ErrInvalidCursor
+		}
+		query = query.Where("(executed_at, id) < (?, ?)", executedAt, cursor.ID)
 	}
 
-	return tx.Commit().Error
-}
-
-func (s *MigrationService) GetMigrationHistory() ([]MigrationResponse, error) {
 	var history []MigrationHistory
-	err := s.db.Order("executed_at desc").Find(&history).Error
+	err = query.Order("executed_at desc, id desc").Limit(page.Limit + 1).Find(&history).Error
 	if err != nil {
-		return nil, err
+		return domain.Page[MigrationResponse]{}, err
 	}
 
-	// Convert to response format with parsed JSON
-	var response []MigrationResponse
+	result := domain.Page[MigrationResponse]{Data: make([]MigrationResponse, 0, len(history))}
+	if len(history) > page.Limit {
+		history = history[:page.Limit]
+		last := history[len(history)-1]
+		result.NextCursor = pagination.Encode(pagination.Cursor{
+			Key:   historyCursorKey,
+			Value: last.ExecutedAt.Format(time.RFC3339Nano),
+			ID:    last.ID,
+		})
+	}
 	for _, h := range history {
-		var changes []SchemaChange
-		if err := json.Unmarshal([]byte(h.SchemaChanges), &changes); err != nil {
-			return nil, fmt.Errorf("failed to parse schema changes: %v", err)
+		response, err := toMigrationResponse(h)
+		if err != nil {
+			return domain.Page[MigrationResponse]{}, err
 		}
-
-		response = append(response, MigrationResponse{
-			ID:            h.ID,
-			TableName:     h.TableName,
-			Operation:     h.Operation,
-			Description:   h.Description,
-			SchemaChanges: changes,
-			ExecutedAt:    h.ExecutedAt,
-			Version:       h.Version,
-			Status:        h.Status,
-			ErrorMessage:  h.ErrorMessage,
-		})
+		result.Data = append(result.Data, response)
 	}
-
-	return response, nil
+	return result, nil
 }
 
 func (s *MigrationService) GetLatestMigration() (*MigrationResponse, error) {
@@ -115,22 +131,31 @@ func (s *MigrationService) GetLatestMigration() (*MigrationResponse, error) {
 		return nil, err
 	}
 
-	var changes []SchemaChange
-	if err := json.Unmarshal([]byte(latest.SchemaChanges), &changes); err != nil {
-		return nil, fmt.Errorf("failed to parse schema changes: %v", err)
+	response, err := toMigrationResponse(latest)
+	if err != nil {
+		return nil, err
 	}
+	return &response, nil
+}
 
-	response := &MigrationResponse{
-		ID:            latest.ID,
-		TableName:     latest.TableName,
-		Operation:     latest.Operation,
-		Description:   latest.Description,
-		SchemaChanges: changes,
-		ExecutedAt:    latest.ExecutedAt,
-		Version:       latest.Version,
-		Status:        latest.Status,
-		ErrorMessage:  latest.ErrorMessage,
+// toMigrationResponse converts a history row, parsing its JSON schema changes.
+func toMigrationResponse(h MigrationHistory) (MigrationResponse, error) {
+	var changes []SchemaChange
+	if err := json.Unmarshal([]byte(h.SchemaChanges), &changes); err != nil {
+		return MigrationResponse{}, fmt.Errorf("failed to parse schema changes: %v", err)
 	}
 
-	return response, nil
+	return MigrationResponse{
+		ID:              h.ID,
+		TableName:       h.TableName,
+		Operation:       h.Operation,
+		Description:     h.Description,
+		SchemaChanges:   changes,
+		ExecutedAt:      h.ExecutedAt,
+		Version:         h.Version,
+		Status:          h.Status,
+		ErrorMessage:    h.ErrorMessage,
+		FailedStatement: h.FailedStatement,
+		DurationMs:      h.DurationMs,
+	}, nil
 }
@@ -0,0 +1,195 @@
+package database
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"embed"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+var (
+	ErrMigrationModified = errors.New("applied migration was modified after it ran")
+	ErrMigrationMissing  = errors.New("applied migration has no migration file")
+	ErrUnknownVersion    = errors.New("unknown migration version")
+	ErrSchemaOutdated    = errors.New("database schema is not at the latest migration")
+	ErrUnknownStatus     = errors.New("unknown migration status")
+)
+
+var migrationFileName = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+// Migration is one numbered step of the schema, read from the files
+// <version>_<name>.up.sql and <version>_<name>.down.sql. Checksum is the SHA-256
+// of the up script; it is recorded when the migration is applied, so editing a
+// migration that already ran is detected instead of silently diverging.
+type Migration struct {
+	Version  int64
+	Name     string
+	Up       string
+	Down     string
+	Checksum string
+}
+
+func (m Migration) String() string {
+	return fmt.Sprintf("%04d_%s", m.Version, m.Name)
+}
+
+// SchemaMigration is the row recorded in schema_migrations for an applied migration.
+type SchemaMigration struct {
+	Version   int64  `gorm:"primaryKey;autoIncrement:false"`
+	Name      string `gorm:"not null"`
+	Checksum  string `gorm:"type:varchar(64);not null"`
+	AppliedAt time.Time
+}
+
+func (SchemaMigration) TableName() string {
+	return "schema_migrations"
+}
+
+// MigrationStatus describes a migration known from its files, the database or
+// both. Modified means the file changed since it was applied; Missing means it
+// was applied but its file is gone.
+type MigrationStatus struct {
+	Version   int64      `json:"version"`
+	Name      string     `json:"name"`
+	Applied   bool       `json:"applied"`
+	AppliedAt *time.Time `json:"applied_at,omitempty"`
+	Modified  bool       `json:"modified,omitempty"`
+	Missing   bool       `json:"missing,omitempty"`
+}
+
+// LoadMigrations reads every migration in the root of fsys, sorted by version.
+// Every version needs both an up and a down script.
+func LoadMigrations(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[int64]*Migration)
+	seen := make(map[string]bool)
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		match := migrationFileName.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, fmt.Errorf("migration file %s: name must look like 0001_name.up.sql", entry.Name())
+		}
+		version, err := strconv.ParseInt(match[1], 10, 64)
+		if err != nil || version == 0 {
+			return nil, fmt.Errorf("migration file %s: version must be a positive number", entry.Name())
+		}
+		content, err := fs.ReadFile(fsys, entry.Name())
+		if err != nil {
+			return nil, err
+		}
+		// Checkouts with CRLF line endings must not look like edited migrations.
+		content = bytes.ReplaceAll(content, []byte("\r\n"), []byte("\n"))
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = m
+		} else if m.Name != match[2] {
+			return nil, fmt.Errorf("migration version %d is used by both %s and %s", version, m.Name, match[2])
+		}
+		// 1_name.up.sql and 0001_name.up.sql are the same version
+		script := fmt.Sprintf("%d.%s", version, match[3])
+		if seen[script] {
+			return nil, fmt.Errorf("migration version %d has more than one %s script", version, match[3])
+		}
+		seen[script] = true
+		if match[3] == "up" {
+			m.Up = string(content)
+			sum := sha256.Sum256(content)
+			m.Checksum = hex.EncodeToString(sum[:])
+		} else {
+			m.Down = string(content)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" || m.Down == "" {
+			return nil, fmt.Errorf("migration %s needs both an up and a down script", m)
+		}
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+// embeddedMigrations are the migrations compiled into the binary from pkg/database/migrations.
+func embeddedMigrations() ([]Migration, error) {
+	sub, err := fs.Sub(migrationFiles, "migrations")
+	if err != nil {
+		return nil, err
+	}
+	return LoadMigrations(sub)
+}
+
+var dollarQuote = regexp.MustCompile(`^\$(\w*)\$`)
+
+// splitStatements splits a migration script at the semicolons that end its
+// statements, skipping those inside quotes, dollar-quoted bodies (such as DO
+// blocks) and comments, so a failure can be traced to a single statement.
+// Comment-only pieces are dropped.
+func splitStatements(script string) []string {
+	var statements []string
+	start, hasCode := 0, false
+	for i := 0; i < len(script); i++ {
+		switch c := script[i]; {
+		case c == '-' && strings.HasPrefix(script[i:], "--"):
+			if end := strings.IndexByte(script[i:], '\n'); end >= 0 {
+				i += end
+			} else {
+				i = len(script)
+			}
+		case c == '/' && strings.HasPrefix(script[i:], "/*"):
+			if end := strings.Index(script[i+2:], "*/"); end >= 0 {
+				i += end + 3
+			} else {
+				i = len(script)
+			}
+		case c == '\'' || c == '"':
+			hasCode = true
+			// A doubled quote is an escaped one and simply reopens the string.
+			if end := strings.IndexByte(script[i+1:], c); end >= 0 {
+				i += end + 1
+			} else {
+				i = len(script)
+			}
+		case c == '$' && dollarQuote.MatchString(script[i:]):
+			hasCode = true
+			tag := dollarQuote.FindString(script[i:])
+			if end := strings.Index(script[i+len(tag):], tag); end >= 0 {
+				i += len(tag) + end + len(tag) - 1
+			} else {
+				i = len(script)
+			}
+		case c == ';':
+			if hasCode {
+				statements = append(statements, strings.TrimSpace(script[start:i+1]))
+			}
+			start, hasCode = i+1, false
+		case !unicode.IsSpace(rune(c)):
+			hasCode = true
+		}
+	}
+	if hasCode {
+		statements = append(statements, strings.TrimSpace(script[start:]))
+	}
+	return statements
+}
@@ -0,0 +1,223 @@
+-- Schema as of the switch from GORM AutoMigrate to versioned migrations.
+--
+-- Databases created by AutoMigrate are adopted rather than recreated: tables
+-- that exist are kept, the columns added to users, notes and note_revisions
+-- since the first release are added, and the free-form notes.is_done column of
+-- that release is carried over to status.
+
+CREATE TABLE IF NOT EXISTS migration_histories (
+    id bigserial PRIMARY KEY,
+    table_name text NOT NULL,
+    operation text NOT NULL,
+    description text NOT NULL,
+    schema_changes text,
+    executed_at timestamptz NOT NULL,
+    version text NOT NULL,
+    status text NOT NULL,
+    error_message text
+);
+
+CREATE TABLE IF NOT EXISTS users (
+    id bigserial PRIMARY KEY,
+    username text NOT NULL,
+    password text NOT NULL,
+    email varchar(255),
+    role varchar(20) NOT NULL DEFAULT 'user',
+    disabled_at timestamptz,
+    created_at timestamptz,
+    updated_at timestamptz,
+    CONSTRAINT uni_users_username UNIQUE (username)
+);
+
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS email varchar(255),
+    ADD COLUMN IF NOT EXISTS role varchar(20) NOT NULL DEFAULT 'user',
+    ADD COLUMN IF NOT EXISTS disabled_at timestamptz;
+
+CREATE TABLE IF NOT EXISTS notebooks (
+    id bigserial PRIMARY KEY,
+    user_id bigint NOT NULL,
+    parent_id bigint,
+    name text NOT NULL,
+    created_at timestamptz,
+    updated_at timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_notebooks_user_id ON notebooks (user_id);
+CREATE INDEX IF NOT EXISTS idx_notebooks_parent_id ON notebooks (parent_id);
+
+CREATE TABLE IF NOT EXISTS notes (
+    id bigserial PRIMARY KEY,
+    user_id bigint,
+    notebook_id bigint,
+    note_title text NOT NULL,
+    content text,
+    status varchar(20) NOT NULL DEFAULT 'todo',
+    completed_at timestamptz,
+    version bigint NOT NULL DEFAULT 1,
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz
+);
+
+ALTER TABLE notes
+    ADD COLUMN IF NOT EXISTS notebook_id bigint,
+    ADD COLUMN IF NOT EXISTS status varchar(20) NOT NULL DEFAULT 'todo',
+    ADD COLUMN IF NOT EXISTS completed_at timestamptz,
+    ADD COLUMN IF NOT EXISTS version bigint NOT NULL DEFAULT 1,
+    ADD COLUMN IF NOT EXISTS deleted_at timestamptz;
+
+DO $$
+BEGIN
+    IF EXISTS (SELECT 1 FROM information_schema.columns
+               WHERE table_schema = current_schema() AND table_name = 'notes' AND column_name = 'is_done') THEN
+        UPDATE notes SET status = 'done', completed_at = COALESCE(completed_at, updated_at)
+        WHERE lower(trim(is_done)) IN ('true', 't', 'yes', 'y', '1', 'done', 'completed');
+        ALTER TABLE notes DROP COLUMN is_done;
+    END IF;
+END $$;
+
+CREATE INDEX IF NOT EXISTS idx_notes_notebook_id ON notes (notebook_id);
+CREATE INDEX IF NOT EXISTS idx_notes_status ON notes (status);
+CREATE INDEX IF NOT EXISTS idx_notes_deleted_at ON notes (deleted_at);
+
+-- Full-text search vector, weighting the title above the content.
+ALTER TABLE notes ADD COLUMN IF NOT EXISTS search_vector tsvector
+    GENERATED ALWAYS AS (
+        setweight(to_tsvector('english', coalesce(note_title, '')), 'A') ||
+        setweight(to_tsvector('english', coalesce(content, '')), 'B')
+    ) STORED;
+CREATE INDEX IF NOT EXISTS idx_notes_search_vector ON notes USING GIN (search_vector);
+
+CREATE TABLE IF NOT EXISTS tags (
+    id bigserial PRIMARY KEY,
+    user_id bigint NOT NULL,
+    name text NOT NULL,
+    created_at timestamptz,
+    updated_at timestamptz
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_tags_user_name ON tags (user_id, name);
+
+CREATE TABLE IF NOT EXISTS note_tags (
+    note_id bigint,
+    tag_id bigint,
+    PRIMARY KEY (note_id, tag_id),
+    CONSTRAINT fk_note_tags_note FOREIGN KEY (note_id) REFERENCES notes (id),
+    CONSTRAINT fk_note_tags_tag FOREIGN KEY (tag_id) REFERENCES tags (id)
+);
+
+CREATE TABLE IF NOT EXISTS note_revisions (
+    id bigserial PRIMARY KEY,
+    note_id bigint NOT NULL,
+    user_id bigint NOT NULL,
+    revision bigint NOT NULL,
+    note_title text,
+    content text,
+    status varchar(20),
+    created_at timestamptz
+);
+
+ALTER TABLE note_revisions ADD COLUMN IF NOT EXISTS status varchar(20);
+
+DO $$
+BEGIN
+    IF EXISTS (SELECT 1 FROM information_schema.columns
+               WHERE table_schema = current_schema() AND table_name = 'note_revisions' AND column_name = 'is_done') THEN
+        UPDATE note_revisions SET status = CASE
+            WHEN lower(trim(is_done)) IN ('true', 't', 'yes', 'y', '1', 'done', 'completed') THEN 'done' ELSE 'todo' END
+        WHERE status IS NULL OR status = '';
+        ALTER TABLE note_revisions DROP COLUMN is_done;
+    END IF;
+END $$;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_note_revisions_note_revision ON note_revisions (note_id, revision);
+CREATE INDEX IF NOT EXISTS idx_note_revisions_user_id ON note_revisions (user_id);
+
+CREATE TABLE IF NOT EXISTS saved_searches (
+    id bigserial PRIMARY KEY,
+    user_id bigint NOT NULL,
+    name text NOT NULL,
+    query text NOT NULL,
+    sort text,
+    created_at timestamptz,
+    updated_at timestamptz
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_saved_searches_user_name ON saved_searches (user_id, name);
+
+CREATE TABLE IF NOT EXISTS sessions (
+    id bigserial PRIMARY KEY,
+    user_id bigint NOT NULL,
+    family_id varchar(64) NOT NULL,
+    user_agent text,
+    ip_address varchar(45),
+    created_at timestamptz,
+    last_used_at timestamptz NOT NULL,
+    revoked_at timestamptz
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_sessions_family_id ON sessions (family_id);
+CREATE INDEX IF NOT EXISTS idx_sessions_user_id ON sessions (user_id);
+
+CREATE TABLE IF NOT EXISTS refresh_tokens (
+    id bigserial PRIMARY KEY,
+    user_id bigint NOT NULL,
+    jti varchar(64) NOT NULL,
+    family_id varchar(64) NOT NULL,
+    token_hash varchar(64) NOT NULL,
+    expires_at timestamptz NOT NULL,
+    used_at timestamptz,
+    revoked_at timestamptz,
+    created_at timestamptz
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_refresh_tokens_jti ON refresh_tokens (jti);
+CREATE INDEX IF NOT EXISTS idx_refresh_tokens_family_id ON refresh_tokens (family_id);
+CREATE INDEX IF NOT EXISTS idx_refresh_tokens_user_id ON refresh_tokens (user_id);
+
+CREATE TABLE IF NOT EXISTS personal_access_tokens (
+    id bigserial PRIMARY KEY,
+    user_id bigint NOT NULL,
+    name text NOT NULL,
+    prefix varchar(16) NOT NULL,
+    token_hash varchar(64) NOT NULL,
+    scopes text NOT NULL,
+    expires_at timestamptz,
+    last_used_at timestamptz,
+    created_at timestamptz
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_personal_access_tokens_token_hash ON personal_access_tokens (token_hash);
+CREATE INDEX IF NOT EXISTS idx_personal_access_tokens_user_id ON personal_access_tokens (user_id);
+
+CREATE TABLE IF NOT EXISTS two_factors (
+    user_id bigint PRIMARY KEY,
+    secret varchar(64) NOT NULL,
+    enabled_at timestamptz,
+    last_used_step bigint NOT NULL DEFAULT 0,
+    created_at timestamptz,
+    updated_at timestamptz
+);
+
+CREATE TABLE IF NOT EXISTS recovery_codes (
+    id bigserial PRIMARY KEY,
+    user_id bigint NOT NULL,
+    code_hash varchar(64) NOT NULL,
+    used_at timestamptz,
+    created_at timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_recovery_codes_user_id ON recovery_codes (user_id);
+
+CREATE TABLE IF NOT EXISTS login_attempts (
+    key varchar(300) PRIMARY KEY,
+    failures bigint NOT NULL DEFAULT 0,
+    last_failure_at timestamptz,
+    blocked_until timestamptz,
+    locked boolean NOT NULL DEFAULT false
+);
+
+CREATE TABLE IF NOT EXISTS password_reset_tokens (
+    id bigserial PRIMARY KEY,
+    user_id bigint NOT NULL,
+    token_hash varchar(64) NOT NULL,
+    expires_at timestamptz NOT NULL,
+    used_at timestamptz,
+    created_at timestamptz
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_password_reset_tokens_token_hash ON password_reset_tokens (token_hash);
+CREATE INDEX IF NOT EXISTS idx_password_reset_tokens_user_id ON password_reset_tokens (user_id);
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_migration_histories_status;
+
+ALTER TABLE migration_histories
+    DROP COLUMN IF EXISTS failed_statement,
+    DROP COLUMN IF EXISTS duration_ms;
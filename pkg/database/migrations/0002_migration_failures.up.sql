@@ -0,0 +1,8 @@
+-- Failed migrations are recorded with the statement that failed and, like
+-- successful ones, how long they ran.
+
+ALTER TABLE migration_histories
+    ADD COLUMN failed_statement text,
+    ADD COLUMN duration_ms bigint NOT NULL DEFAULT 0;
+
+CREATE INDEX idx_migration_histories_status ON migration_histories (status);
@@ -0,0 +1,16 @@
+-- Password resets are only mailed to verified addresses. Addresses given before
+-- verification existed stay unverified until their owner confirms them.
+
+ALTER TABLE users ADD COLUMN email_verified_at timestamptz;
+
+CREATE TABLE email_verification_tokens (
+    id bigserial PRIMARY KEY,
+    user_id bigint NOT NULL,
+    email varchar(255) NOT NULL,
+    token_hash varchar(64) NOT NULL,
+    expires_at timestamptz NOT NULL,
+    used_at timestamptz,
+    created_at timestamptz
+);
+CREATE UNIQUE INDEX idx_email_verification_tokens_token_hash ON email_verification_tokens (token_hash);
+CREATE INDEX idx_email_verification_tokens_user_id ON email_verification_tokens (user_id);
@@ -0,0 +1,222 @@
+package database
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func migrationFS(files map[string]string) fstest.MapFS {
+	fsys := make(fstest.MapFS, len(files))
+	for name, content := range files {
+		fsys[name] = &fstest.MapFile{Data: []byte(content)}
+	}
+	return fsys
+}
+
+func TestLoadMigrationsOrder(t *testing.T) {
+	migrations, err := LoadMigrations(migrationFS(map[string]string{
+		"0010_tenth.up.sql":    "CREATE TABLE ten (id int);",
+		"0010_tenth.down.sql":  "DROP TABLE ten;",
+		"0002_second.up.sql":   "CREATE TABLE two (id int);",
+		"0002_second.down.sql": "DROP TABLE two;",
+		"0001_first.up.sql":    "CREATE TABLE one (id int);\r\n",
+		"0001_first.down.sql":  "DROP TABLE one;",
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var names []string
+	for _, m := range migrations {
+		names = append(names, m.String())
+	}
+	if want := []string{"0001_first", "0002_second", "0010_tenth"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("migrations = %v, want %v", names, want)
+	}
+	if migrations[0].Up != "CREATE TABLE one (id int);\n" {
+		t.Errorf("CRLF line endings were not normalized: %q", migrations[0].Up)
+	}
+	if migrations[1].Down != "DROP TABLE two;" {
+		t.Errorf("down script = %q", migrations[1].Down)
+	}
+}
+
+func TestLoadMigrationsEmbedded(t *testing.T) {
+	migrations, err := embeddedMigrations()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, m := range migrations {
+		if m.Version != int64(i+1) {
+			t.Errorf("migration %s: versions must be consecutive from 1", m)
+		}
+	}
+}
+
+func TestLoadMigrationsErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+		want  string
+	}{
+		{
+			name:  "missing down script",
+			files: map[string]string{"0001_first.up.sql": "SELECT 1;"},
+			want:  "0001_first needs both an up and a down script",
+		},
+		{
+			name:  "missing up script",
+			files: map[string]string{"0001_first.down.sql": "SELECT 1;"},
+			want:  "0001_first needs both an up and a down script",
+		},
+		{
+			name: "duplicate version with different names",
+			files: map[string]string{
+				"0001_first.up.sql":   "SELECT 1;",
+				"0001_first.down.sql": "SELECT 1;",
+				"0001_other.up.sql":   "SELECT 2;",
+				"0001_other.down.sql": "SELECT 2;",
+			},
+			want: "migration version 1 is used by both",
+		},
+		{
+			name: "duplicate version with different padding",
+			files: map[string]string{
+				"0001_first.up.sql":   "SELECT 1;",
+				"0001_first.down.sql": "SELECT 1;",
+				"1_first.up.sql":      "SELECT 2;",
+			},
+			want: "migration version 1 has more than one up script",
+		},
+		{
+			name:  "badly named file",
+			files: map[string]string{"first.sql": "SELECT 1;"},
+			want:  "name must look like 0001_name.up.sql",
+		},
+		{
+			name:  "version zero",
+			files: map[string]string{"0000_zero.up.sql": "SELECT 1;"},
+			want:  "version must be a positive number",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := LoadMigrations(migrationFS(tt.files))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("LoadMigrations = %v, want an error containing %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestVerifyChecksums(t *testing.T) {
+	files := map[string]string{
+		"0001_first.up.sql":    "CREATE TABLE one (id int);",
+		"0001_first.down.sql":  "DROP TABLE one;",
+		"0002_second.up.sql":   "CREATE TABLE two (id int);",
+		"0002_second.down.sql": "DROP TABLE two;",
+	}
+	migrations, err := LoadMigrations(migrationFS(files))
+	if err != nil {
+		t.Fatal(err)
+	}
+	applied := []SchemaMigration{
+		{Version: 1, Name: "first", Checksum: migrations[0].Checksum},
+		{Version: 2, Name: "second", Checksum: migrations[1].Checksum},
+	}
+
+	s := &MigrationService{migrations: migrations}
+	if err := s.verify(applied); err != nil {
+		t.Fatalf("verify of unchanged migrations: %v", err)
+	}
+
+	// A checkout with CRLF line endings is not an edit
+	files["0002_second.up.sql"] = "CREATE TABLE two (id int);\n"
+	lf, err := LoadMigrations(migrationFS(files))
+	if err != nil {
+		t.Fatal(err)
+	}
+	files["0002_second.up.sql"] = "CREATE TABLE two (id int);\r\n"
+	crlf, err := LoadMigrations(migrationFS(files))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if crlf[1].Checksum != lf[1].Checksum {
+		t.Error("CRLF line endings changed the checksum")
+	}
+
+	files["0002_second.up.sql"] = "CREATE TABLE two (id bigint);"
+	edited, err := LoadMigrations(migrationFS(files))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s = &MigrationService{migrations: edited}
+	if err := s.verify(applied); !errors.Is(err, ErrMigrationModified) {
+		t.Errorf("verify of an edited migration = %v, want ErrMigrationModified", err)
+	}
+
+	s = &MigrationService{migrations: migrations[:1]}
+	if err := s.verify(applied); !errors.Is(err, ErrMigrationMissing) {
+		t.Errorf("verify of a deleted migration = %v, want ErrMigrationMissing", err)
+	}
+}
+
+func TestSplitStatements(t *testing.T) {
+	tests := []struct {
+		name   string
+		script string
+		want   []string
+	}{
+		{
+			name:   "plain statements",
+			script: "CREATE TABLE a (id int);\nCREATE TABLE b (id int);\n",
+			want:   []string{"CREATE TABLE a (id int);", "CREATE TABLE b (id int);"},
+		},
+		{
+			name:   "last statement without semicolon",
+			script: "SELECT 1;\nSELECT 2",
+			want:   []string{"SELECT 1;", "SELECT 2"},
+		},
+		{
+			name:   "semicolon in a string literal",
+			script: "INSERT INTO a VALUES ('x;y');\nINSERT INTO a VALUES ('it''s; fine');",
+			want:   []string{"INSERT INTO a VALUES ('x;y');", "INSERT INTO a VALUES ('it''s; fine');"},
+		},
+		{
+			name:   "semicolon in a quoted identifier",
+			script: `CREATE TABLE "a;b" (id int);`,
+			want:   []string{`CREATE TABLE "a;b" (id int);`},
+		},
+		{
+			name: "dollar-quoted DO block",
+			script: "DO $$\nBEGIN\n  PERFORM 1;\n  PERFORM 2;\nEND\n$$;\n" +
+				"SELECT 3;",
+			want: []string{"DO $$\nBEGIN\n  PERFORM 1;\n  PERFORM 2;\nEND\n$$;", "SELECT 3;"},
+		},
+		{
+			name:   "tagged dollar quote containing $$",
+			script: "CREATE FUNCTION f() RETURNS text AS $body$ SELECT '$$;'; $body$ LANGUAGE sql;\nSELECT 1;",
+			want:   []string{"CREATE FUNCTION f() RETURNS text AS $body$ SELECT '$$;'; $body$ LANGUAGE sql;", "SELECT 1;"},
+		},
+		{
+			name:   "comments",
+			script: "-- setup; nothing here\nSELECT 1; /* a; b */\n-- trailing;\n",
+			want:   []string{"-- setup; nothing here\nSELECT 1;"},
+		},
+		{
+			name:   "empty statements are dropped",
+			script: ";;\n  ;SELECT 1;",
+			want:   []string{"SELECT 1;"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := splitStatements(tt.script); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("splitStatements(%q)\n got  %q\n want %q", tt.script, got, tt.want)
+			}
+		})
+	}
+}
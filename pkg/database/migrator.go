@@ -0,0 +1,260 @@
+package database
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	directionUp   = "UP"
+	directionDown = "DOWN"
+)
+
+// migrationStep is a migration to run in one direction.
+type migrationStep struct {
+	migration Migration
+	direction string
+}
+
+func (s migrationStep) script() string {
+	if s.direction == directionUp {
+		return s.migration.Up
+	}
+	return s.migration.Down
+}
+
+// Up applies every pending migration in version order.
+func (s *MigrationService) Up() error {
+	if len(s.migrations) == 0 {
+		return nil
+	}
+	return s.Goto(s.migrations[len(s.migrations)-1].Version)
+}
+
+// Down rolls back the n most recently applied migrations, newest first.
+func (s *MigrationService) Down(n int) error {
+	return s.WithLock(func() error {
+		applied, err := s.verifiedApplied()
+		if err != nil {
+			return err
+		}
+
+		var steps []migrationStep
+		for i := len(applied) - 1; i >= 0 && len(steps) < n; i-- {
+			steps = append(steps, migrationStep{migration: s.byVersion(applied[i].Version), direction: directionDown})
+		}
+		return s.run(steps)
+	})
+}
+
+// Goto migrates to version: migrations above it are rolled back, newest first,
+// then pending ones up to it are applied, oldest first. Version 0 rolls back
+// everything. Up, Down and Goto plan and run under the migration lock, so an
+// instance that waited for another one finds the work already done.
+func (s *MigrationService) Goto(version int64) error {
+	if version != 0 && s.byVersion(version).Version == 0 {
+		return fmt.Errorf("%w: %d", ErrUnknownVersion, version)
+	}
+	return s.WithLock(func() error {
+		steps, err := s.plan(version)
+		if err != nil {
+			return err
+		}
+		return s.run(steps)
+	})
+}
+
+// Pending lists the migrations Up would apply, in order.
+func (s *MigrationService) Pending() ([]Migration, error) {
+	if len(s.migrations) == 0 {
+		return nil, nil
+	}
+	steps, err := s.plan(s.migrations[len(s.migrations)-1].Version)
+	if err != nil {
+		return nil, err
+	}
+
+	pending := make([]Migration, len(steps))
+	for i, step := range steps {
+		pending[i] = step.migration
+	}
+	return pending, nil
+}
+
+// Verify fails unless every migration is applied unmodified, which is what the
+// app needs before it serves requests.
+func (s *MigrationService) Verify() error {
+	pending, err := s.Pending()
+	if err != nil {
+		return err
+	}
+	if len(pending) > 0 {
+		return fmt.Errorf("%w: %d pending, starting with %s", ErrSchemaOutdated, len(pending), pending[0])
+	}
+	return nil
+}
+
+// Status lists every migration, applied or not, by version.
+func (s *MigrationService) Status() ([]MigrationStatus, error) {
+	applied, err := s.applied()
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make(map[int64]*MigrationStatus)
+	for _, m := range s.migrations {
+		statuses[m.Version] = &MigrationStatus{Version: m.Version, Name: m.Name}
+	}
+	for _, row := range applied {
+		appliedAt := row.AppliedAt
+		status, ok := statuses[row.Version]
+		if !ok {
+			status = &MigrationStatus{Version: row.Version, Name: row.Name, Missing: true}
+			statuses[row.Version] = status
+		}
+		status.Applied = true
+		status.AppliedAt = &appliedAt
+		status.Modified = ok && s.byVersion(row.Version).Checksum != row.Checksum
+	}
+
+	result := make([]MigrationStatus, 0, len(statuses))
+	for _, status := range statuses {
+		result = append(result, *status)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Version < result[j].Version })
+	return result, nil
+}
+
+// plan lists the steps that bring the schema to version.
+func (s *MigrationService) plan(version int64) ([]migrationStep, error) {
+	applied, err := s.verifiedApplied()
+	if err != nil {
+		return nil, err
+	}
+
+	isApplied := make(map[int64]bool, len(applied))
+	var steps []migrationStep
+	for i := len(applied) - 1; i >= 0; i-- {
+		isApplied[applied[i].Version] = true
+		if applied[i].Version > version {
+			steps = append(steps, migrationStep{migration: s.byVersion(applied[i].Version), direction: directionDown})
+		}
+	}
+	for _, m := range s.migrations {
+		if m.Version <= version && !isApplied[m.Version] {
+			steps = append(steps, migrationStep{migration: m, direction: directionUp})
+		}
+	}
+	return steps, nil
+}
+
+// run executes steps in order, each in its own transaction together with its
+// schema_migrations bookkeeping, and stops at the first failure. A failed step
+// is rolled back with its transaction and then recorded as FAILED, with the
+// statement that failed, outside of it.
+func (s *MigrationService) run(steps []migrationStep) error {
+	for _, step := range steps {
+		m := step.migration
+		start := time.Now()
+		var failedStatement string
+		err := s.db.Transaction(func(tx *gorm.DB) error {
+			for _, statement := range splitStatements(step.script()) {
+				if err := tx.Exec(statement).Error; err != nil {
+					failedStatement = statement
+					return err
+				}
+			}
+
+			if step.direction == directionUp {
+				err := tx.Create(&SchemaMigration{Version: m.Version, Name: m.Name, Checksum: m.Checksum, AppliedAt: time.Now()}).Error
+				if err != nil {
+					return err
+				}
+			} else if err := tx.Delete(&SchemaMigration{}, m.Version).Error; err != nil {
+				return err
+			}
+
+			return recordStep(tx, step, MigrationHistory{
+				Status:     MigrationSucceeded,
+				DurationMs: time.Since(start).Milliseconds(),
+			})
+		})
+		if err != nil {
+			history := MigrationHistory{
+				Status:          MigrationFailed,
+				ErrorMessage:    err.Error(),
+				FailedStatement: failedStatement,
+				DurationMs:      time.Since(start).Milliseconds(),
+			}
+			if recordErr := recordStep(s.db, step, history); recordErr != nil {
+				log.Printf("Warning: failed to record failed migration %s: %v", m, recordErr)
+			}
+			return fmt.Errorf("migration %s %s failed and was rolled back: %w", m, step.direction, err)
+		}
+		log.Printf("Migration %s %s done in %s", m, step.direction, time.Since(start).Round(time.Millisecond))
+	}
+	return nil
+}
+
+// recordStep adds the history row for step. Before 0002 is applied, and after
+// it is rolled back, migration_histories has no failed_statement or
+// duration_ms column to write.
+func recordStep(db *gorm.DB, step migrationStep, history MigrationHistory) error {
+	history.TableName = "schema_migrations"
+	history.Operation = step.direction
+	history.Description = step.migration.String()
+	history.SchemaChanges = "[]"
+	history.ExecutedAt = time.Now()
+	history.Version = fmt.Sprint(step.migration.Version)
+
+	if !db.Migrator().HasColumn(&MigrationHistory{}, "duration_ms") {
+		db = db.Omit("FailedStatement", "DurationMs")
+	}
+	return db.Create(&history).Error
+}
+
+// verifiedApplied returns the applied migrations, refusing to continue when one
+// was edited or deleted after it ran.
+func (s *MigrationService) verifiedApplied() ([]SchemaMigration, error) {
+	applied, err := s.applied()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.verify(applied); err != nil {
+		return nil, err
+	}
+	return applied, nil
+}
+
+// verify checks every applied migration still has its file, unchanged.
+func (s *MigrationService) verify(applied []SchemaMigration) error {
+	for _, row := range applied {
+		m := s.byVersion(row.Version)
+		if m.Version == 0 {
+			return fmt.Errorf("%w: %d_%s", ErrMigrationMissing, row.Version, row.Name)
+		}
+		if m.Checksum != row.Checksum {
+			return fmt.Errorf("%w: %s", ErrMigrationModified, m)
+		}
+	}
+	return nil
+}
+
+func (s *MigrationService) applied() ([]SchemaMigration, error) {
+	var applied []SchemaMigration
+	err := s.db.Order("version").Find(&applied).Error
+	return applied, err
+}
+
+func (s *MigrationService) byVersion(version int64) Migration {
+	for _, m := range s.migrations {
+		if m.Version == version {
+			return m
+		}
+	}
+	return Migration{}
+}
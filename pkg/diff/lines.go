@@ -0,0 +1,115 @@
+package diff
+
+import "strings"
+
+const (
+	OpEqual  = "equal"
+	OpInsert = "insert"
+	OpDelete = "delete"
+)
+
+// maxLCSCells caps the size of the LCS table (len(from) * len(to) of the changed
+// middle). Larger changes get a coarse diff instead, so a revision diff of two
+// huge, unrelated notes cannot use quadratic time and memory.
+const maxLCSCells = 1 << 20
+
+// Line is one line of a line-based diff.
+type Line struct {
+	Op   string `json:"op"`
+	Text string `json:"text"`
+}
+
+// Lines computes a line-based diff turning a into b using the longest common
+// subsequence. When the changed region is too large for that, the region is
+// reported as deleted and re-inserted as a whole.
+func Lines(a, b string) []Line {
+	from := splitLines(a)
+	to := splitLines(b)
+
+	// Common prefix and suffix never need the LCS table
+	prefix := 0
+	for prefix < len(from) && prefix < len(to) && from[prefix] == to[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(from)-prefix && suffix < len(to)-prefix &&
+		from[len(from)-1-suffix] == to[len(to)-1-suffix] {
+		suffix++
+	}
+
+	var result []Line
+	for _, text := range from[:prefix] {
+		result = append(result, Line{Op: OpEqual, Text: text})
+	}
+	result = append(result, lcsDiff(from[prefix:len(from)-suffix], to[prefix:len(to)-suffix])...)
+	for _, text := range from[len(from)-suffix:] {
+		result = append(result, Line{Op: OpEqual, Text: text})
+	}
+	return result
+}
+
+func lcsDiff(from, to []string) []Line {
+	if len(from) > 0 && len(to) > maxLCSCells/len(from) {
+		return coarseDiff(from, to)
+	}
+
+	// lengths[i][j] is the LCS length of from[i:] and to[j:]
+	lengths := make([][]int, len(from)+1)
+	for i := range lengths {
+		lengths[i] = make([]int, len(to)+1)
+	}
+	for i := len(from) - 1; i >= 0; i-- {
+		for j := len(to) - 1; j >= 0; j-- {
+			if from[i] == to[j] {
+				lengths[i][j] = lengths[i+1][j+1] + 1
+			} else if lengths[i+1][j] >= lengths[i][j+1] {
+				lengths[i][j] = lengths[i+1][j]
+			} else {
+				lengths[i][j] = lengths[i][j+1]
+			}
+		}
+	}
+
+	var result []Line
+	i, j := 0, 0
+	for i < len(from) && j < len(to) {
+		switch {
+		case from[i] == to[j]:
+			result = append(result, Line{Op: OpEqual, Text: from[i]})
+			i++
+			j++
+		case lengths[i+1][j] >= lengths[i][j+1]:
+			result = append(result, Line{Op: OpDelete, Text: from[i]})
+			i++
+		default:
+			result = append(result, Line{Op: OpInsert, Text: to[j]})
+			j++
+		}
+	}
+	for ; i < len(from); i++ {
+		result = append(result, Line{Op: OpDelete, Text: from[i]})
+	}
+	for ; j < len(to); j++ {
+		result = append(result, Line{Op: OpInsert, Text: to[j]})
+	}
+	return result
+}
+
+// coarseDiff deletes every line of from and inserts every line of to.
+func coarseDiff(from, to []string) []Line {
+	result := make([]Line, 0, len(from)+len(to))
+	for _, text := range from {
+		result = append(result, Line{Op: OpDelete, Text: text})
+	}
+	for _, text := range to {
+		result = append(result, Line{Op: OpInsert, Text: text})
+	}
+	return result
+}
+
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
@@ -0,0 +1,82 @@
+// Package mailer sends the emails the app needs, such as password reset links.
+// Deployments plug in a Mailer for their provider; LogMailer and FileMailer are
+// for local use.
+package mailer
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Message is one email. Secrets lists values in the body, such as tokens, that
+// must not end up anywhere but the recipient's inbox.
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+	Secrets []string
+}
+
+type Mailer interface {
+	Send(msg Message) error
+}
+
+// LogMailer writes messages to the application log instead of sending them,
+// with their secrets redacted; use FileMailer to read the full messages.
+type LogMailer struct{}
+
+func (LogMailer) Send(msg Message) error {
+	body := msg.Body
+	for _, secret := range msg.Secrets {
+		if secret != "" {
+			body = strings.ReplaceAll(body, secret, "[redacted]")
+		}
+	}
+	log.Printf("Mail to %s: %s\n%s", msg.To, msg.Subject, body)
+	return nil
+}
+
+// FileMailer writes each message to its own .eml file in Dir, which mail
+// clients can open.
+type FileMailer struct {
+	Dir  string
+	From string
+}
+
+func (m FileMailer) Send(msg Message) error {
+	if err := os.MkdirAll(m.Dir, 0o755); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	name := fmt.Sprintf("%s-%s.eml", now.Format("20060102T150405.000000000"), sanitize(msg.To))
+	content := fmt.Sprintf("From: %s\r\nTo: %s\r\nSubject: %s\r\nDate: %s\r\n\r\n%s\r\n",
+		m.From, msg.To, msg.Subject, now.Format(time.RFC1123Z), msg.Body)
+	return os.WriteFile(filepath.Join(m.Dir, name), []byte(content), 0o600)
+}
+
+// New picks the mailer named by kind: "log" or "file", which writes to dir.
+func New(kind, dir, from string) (Mailer, error) {
+	switch kind {
+	case "log":
+		return LogMailer{}, nil
+	case "file":
+		return FileMailer{Dir: dir, From: from}, nil
+	default:
+		return nil, fmt.Errorf("unknown mailer %q", kind)
+	}
+}
+
+func sanitize(address string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '@' || r == '.' || r == '-' || r == '_' ||
+			(r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+			return r
+		}
+		return '_'
+	}, address)
+}
@@ -0,0 +1,41 @@
+package pagination
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Cursor marks the last row of a page in keyset order. Key names the ordering
+// the cursor was issued for so it cannot be replayed against a different sort.
+type Cursor struct {
+	Key   string `json:"k"`
+	Value string `json:"v"`
+	ID    uint   `json:"id"`
+}
+
+// Encode turns a cursor into the opaque token handed to clients.
+func Encode(c Cursor) string {
+	raw, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+// Decode parses a token produced by Encode. An empty token yields a nil cursor.
+func Decode(token string) (*Cursor, error) {
+	if token == "" {
+		return nil, nil
+	}
+
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	var c Cursor
+	if err := json.Unmarshal(raw, &c); err != nil || c.Key == "" {
+		return nil, ErrInvalidCursor
+	}
+	return &c, nil
+}
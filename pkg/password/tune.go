@@ -0,0 +1,45 @@
+package password
+
+import (
+	"time"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// TuneArgon2 benchmarks argon2id on this host and returns the smallest time
+// cost whose hash takes at least target with the given memory (KiB) and
+// threads. Memory is the parameter to raise first, as far as the host can
+// spare for concurrent logins; time then fills the remaining budget.
+func TuneArgon2(target time.Duration, memory uint32, threads uint8) (Argon2Params, time.Duration) {
+	params := DefaultArgon2Params
+	params.Memory = memory
+	params.Threads = threads
+
+	salt := make([]byte, params.SaltLength)
+	var took time.Duration
+	for params.Time = 1; ; params.Time++ {
+		start := time.Now()
+		argon2.IDKey([]byte("benchmark password"), salt, params.Time, params.Memory, params.Threads, params.KeyLength)
+		took = time.Since(start)
+		if took >= target || params.Time >= 100 {
+			return params, took
+		}
+	}
+}
+
+// TuneBcrypt returns the smallest bcrypt cost whose hash takes at least target
+// on this host, and how long it took.
+func TuneBcrypt(target time.Duration) (int, time.Duration) {
+	var took time.Duration
+	for cost := bcrypt.MinCost; ; cost++ {
+		start := time.Now()
+		if _, err := bcrypt.GenerateFromPassword([]byte("benchmark password"), cost); err != nil {
+			return cost - 1, took
+		}
+		took = time.Since(start)
+		if took >= target || cost >= bcrypt.MaxCost {
+			return cost, took
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package totp
+
+import (
+	"testing"
+	"time"
+)
+
+// rfcSecret is the RFC 6238 SHA1 test key "12345678901234567890", base32 encoded.
+const rfcSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+// The RFC 6238 appendix B vectors are 8 digits; these are their last 6.
+var rfcVectors = []struct {
+	unix int64
+	code string
+}{
+	{59, "287082"},
+	{1111111109, "081804"},
+	{1234567890, "005924"},
+	{2000000000, "279037"},
+}
+
+func TestCodeRFC6238Vectors(t *testing.T) {
+	for _, v := range rfcVectors {
+		code, err := Code(rfcSecret, time.Unix(v.unix, 0))
+		if err != nil {
+			t.Fatalf("Code(T=%d): %v", v.unix, err)
+		}
+		if code != v.code {
+			t.Errorf("Code(T=%d) = %s, want %s", v.unix, code, v.code)
+		}
+	}
+}
+
+func TestVerifyRFC6238Vectors(t *testing.T) {
+	for _, v := range rfcVectors {
+		now := time.Unix(v.unix, 0)
+		step, ok := Verify(rfcSecret, v.code, now, 0)
+		if !ok {
+			t.Errorf("Verify(T=%d, %s) rejected a valid code", v.unix, v.code)
+			continue
+		}
+		if step != Step(now) {
+			t.Errorf("Verify(T=%d) step = %d, want %d", v.unix, step, Step(now))
+		}
+	}
+}
+
+func TestVerifyWindow(t *testing.T) {
+	issued := time.Unix(1234567890, 0)
+	code, err := Code(rfcSecret, issued)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		offset time.Duration
+		ok     bool
+	}{
+		{"two steps early", -2 * Period, false},
+		{"one step early", -Period, true},
+		{"same step", 0, true},
+		{"one step late", Period, true},
+		{"two steps late", 2 * Period, false},
+	}
+	for _, tt := range tests {
+		step, ok := Verify(rfcSecret, code, issued.Add(tt.offset), 1)
+		if ok != tt.ok {
+			t.Errorf("%s: Verify = %v, want %v", tt.name, ok, tt.ok)
+		}
+		if ok && step != Step(issued) {
+			t.Errorf("%s: step = %d, want the step the code was issued in (%d)", tt.name, step, Step(issued))
+		}
+	}
+}
+
+func TestVerifyRejectsMalformedCodes(t *testing.T) {
+	now := time.Unix(59, 0)
+	for _, code := range []string{"", "28708", "2870820", "94287082", "abcdef"} {
+		if _, ok := Verify(rfcSecret, code, now, 1); ok {
+			t.Errorf("Verify(%q) accepted a malformed code", code)
+		}
+	}
+	if _, ok := Verify("not base32!", "287082", now, 1); ok {
+		t.Error("Verify accepted a code for an invalid secret")
+	}
+}
+
+// TestVerifyReplay checks that the step Verify reports lets a caller refuse a
+// code that was already used, including an older code still inside the window,
+// the same way the two-factor repository compares against last_used_step.
+func TestVerifyReplay(t *testing.T) {
+	var lastUsedStep int64
+	use := func(code string, now time.Time) bool {
+		step, ok := Verify(rfcSecret, code, now, 1)
+		if !ok || step <= lastUsedStep {
+			return false
+		}
+		lastUsedStep = step
+		return true
+	}
+
+	now := time.Unix(1111111109, 0)
+	previous, _ := Code(rfcSecret, now.Add(-Period))
+	current, _ := Code(rfcSecret, now)
+
+	if !use(current, now) {
+		t.Fatal("first use of the current code was rejected")
+	}
+	if use(current, now) {
+		t.Error("replay of the current code was accepted")
+	}
+	if use(current, now.Add(Period)) {
+		t.Error("replay of the current code in the next step was accepted")
+	}
+	if use(previous, now) {
+		t.Error("older code was accepted after a newer one was used")
+	}
+
+	next, _ := Code(rfcSecret, now.Add(Period))
+	if !use(next, now.Add(Period)) {
+		t.Error("code of the next step was rejected")
+	}
+}
@@ -11,6 +11,8 @@ type TokenPair struct {
 }
 
 type UserClaims struct {
-	ID   uint   `json:"user_id"`
-	Type string `json:"token_type"`
+	ID        uint     `json:"user_id"`
+	Type      string   `json:"token_type"`
+	SessionID string   `json:"sid"`
+	Scopes    []string `json:"scopes,omitempty"`
 }